@@ -1,17 +1,21 @@
 package cmd
 
 import (
-	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 
 	"github.com/amaterasu/markdex-cli/internal/api"
-	"github.com/amaterasu/markdex-cli/internal/cache"
+	"github.com/amaterasu/markdex-cli/internal/clipboard"
 	"github.com/amaterasu/markdex-cli/internal/config"
+	"github.com/amaterasu/markdex-cli/internal/output"
+	"github.com/amaterasu/markdex-cli/internal/pagemeta"
+	"github.com/amaterasu/markdex-cli/internal/urlnorm"
 )
 
 var (
@@ -21,58 +25,207 @@ var (
 	addFlagDesc       string
 	addFlagSourceFile string
 	addFlagJSON       bool
+	addFlagPaste      bool
+	addFlagNoFetch    bool
+	addFlagForce      bool
+	addFlagUpdate     bool
+	addFlagFromFile   string
+	addFlagWorkers    int
+	addFlagRate       float64
 )
 
 var addCmd = &cobra.Command{
-	Use:   "add <url>",
+	Use:   "add <url | ->",
 	Short: "Add a new bookmark (AI or manual)",
-	Args:  cobra.ExactArgs(1),
+	Long: "Add a new bookmark (AI or manual). With - (stdin) or --from-file, every http(s) URL in the input\n" +
+		"(one per line, or inside markdown or plain text) is added with the same flags, several at a time;\n" +
+		"a report of each URL follows and the exit status is non-zero if any failed. URLs that are already\n" +
+		"bookmarked are reported as exists (use --update to add the tags to them) and are not failures.",
+	Args: cobra.RangeArgs(0, 1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		url := strings.TrimSpace(args[0])
+		cfg, _ := config.Load()
+		if (len(args) == 1 && args[0] == "-") || addFlagFromFile != "" {
+			return addBatch(cfg, args)
+		}
+		var url string
+		switch {
+		case len(args) == 1:
+			url = strings.TrimSpace(args[0])
+		case addFlagPaste:
+			text, err := clipboard.Read(cfg.Clipboard)
+			if err != nil {
+				return err
+			}
+			url = strings.TrimSpace(text)
+		}
 		if url == "" {
 			return errors.New("url required")
 		}
-		cfg, _ := config.Load()
 		base := firstNonEmpty(apiBase, cfg.APIBase)
 		if base == "" {
 			return fmt.Errorf("API base not set (use markdex config set --api <url>)")
 		}
 
-		// If AI mode, ignore manual fields (except source-file) unless provided.
-		req := api.CreateBookmarkRequest{
-			URL:         url,
-			AI:          addFlagAI,
-			Title:       addFlagTitle,
-			Tags:        addFlagTags,
-			Description: addFlagDesc,
-			SourceFile:  addFlagSourceFile,
-		}
-		if addFlagAI {
-			// In AI mode, we allow explicit Title/Tags/Description overrides if user passed them; backend may fill missing.
-		}
-		bk, err := api.CreateBookmark(base, req)
+		proj, err := currentProject()
 		if err != nil {
 			return err
 		}
-		if addFlagJSON {
-			enc := json.NewEncoder(os.Stdout)
-			enc.SetIndent("", "  ")
-			return enc.Encode(bk)
+		opts := newAddOptions(cfg)
+		req := addRequest(opts, proj, url)
+		bk, status, err := createOrMerge(base, opts, existingBookmarks(base, cfg), req, os.Stderr)
+		if err != nil {
+			return err
 		}
-
 		// Invalidate local cache so next list/pick reflects new bookmark
-		c := cache.New()
-		_ = os.Remove(c.Path)
-		fmt.Printf("Added %s (%s) tags=%v\n", bk.Title, bk.Hash, bk.Tags)
+		invalidateCache(cfg)
+		if outTemplate != nil || format(addFlagJSON) != output.Table {
+			return printBookmark(bk, addFlagJSON)
+		}
+		switch status {
+		case "updated":
+			fmt.Printf("Updated %s (%s) tags=%v\n", bk.Title, bk.Hash, bk.Tags)
+		case "unchanged":
+			fmt.Printf("Already bookmarked with these tags: %s (%s) tags=%v\n", bk.Title, bk.Hash, bk.Tags)
+		default:
+			fmt.Printf("Added %s (%s) tags=%v\n", bk.Title, bk.Hash, bk.Tags)
+		}
 		return nil
 	},
 }
 
+// addOptions is what creating a bookmark needs from the flags and config, read once so
+// that batch workers share no mutable state.
+type addOptions struct {
+	norm    urlnorm.Rules
+	update  bool          // --update: merge tags into an existing bookmark
+	fetch   bool          // prefill manual adds from the page (no --no-fetch)
+	timeout time.Duration // for page fetches
+}
+
+func newAddOptions(cfg *config.Config) addOptions {
+	return addOptions{norm: cfg.URLNormalizer(), update: addFlagUpdate, fetch: !addFlagNoFetch, timeout: cfg.Timeout}
+}
+
+// addRequest builds the create request for url (cleaned up by the urlRules) from the flags
+// and the project defaults (proj may be nil).
+func addRequest(opts addOptions, proj *config.Project, url string) api.CreateBookmarkRequest {
+	// If AI mode, ignore manual fields (except source-file) unless provided.
+	req := api.CreateBookmarkRequest{
+		URL:         opts.norm.Normalize(url),
+		AI:          addFlagAI,
+		Title:       addFlagTitle,
+		Tags:        append([]string(nil), addFlagTags...),
+		Description: addFlagDesc,
+		SourceFile:  addFlagSourceFile,
+	}
+	// Apply project defaults from .markdex.toml (explicit flags win for the source file).
+	if proj != nil {
+		req.Tags = mergeTags(req.Tags, proj.Tags)
+		req.SourceFile = firstNonEmpty(req.SourceFile, proj.SourceFile)
+	}
+	if addFlagAI {
+		// In AI mode, we allow explicit Title/Tags/Description overrides if user passed them; backend may fill missing.
+	}
+	return req
+}
+
+// existingBookmarks returns the collection to check new URLs against; nil with --force,
+// or with a warning when it cannot be loaded.
+func existingBookmarks(base string, cfg *config.Config) []api.Bookmark {
+	if addFlagForce {
+		return nil
+	}
+	items, err := loadBookmarks(base, cfg, false)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "warning: cannot check for duplicates: %v\n", err)
+	}
+	return items
+}
+
+// duplicateError reports that a URL is already bookmarked.
+type duplicateError struct {
+	existing api.Bookmark
+}
+
+func (e *duplicateError) Error() string {
+	return fmt.Sprintf("already bookmarked as %s (%s); use --force to add it anyway or --update to add the tags to it", e.existing.Hash, e.existing.Title)
+}
+
+// createOrMerge creates the bookmark unless items already has its URL: then --update adds
+// the new tags to the existing one, otherwise it is a duplicateError. Before creating, a
+// manual add is filled in from the page (see prefill), whose canonical URL is checked too;
+// fetch problems are reported to warn. status tells what happened: added, updated or
+// unchanged (--update without new tags).
+func createOrMerge(base string, opts addOptions, items []api.Bookmark, req api.CreateBookmarkRequest, warn io.Writer) (bk api.Bookmark, status string, err error) {
+	if b, ok := findDuplicate(opts.norm, items, req.URL); ok {
+		return mergeInto(base, opts, b, req)
+	}
+	canonical, err := prefill(opts, &req)
+	if err != nil {
+		fmt.Fprintf(warn, "warning: cannot read %s: %v\n", req.URL, err)
+	}
+	if b, ok := findDuplicate(opts.norm, items, canonical); ok {
+		return mergeInto(base, opts, b, req)
+	}
+	bk, err = api.CreateBookmark(base, req)
+	return bk, "added", err
+}
+
+// findDuplicate returns the bookmark in items for the same page as u.
+func findDuplicate(norm urlnorm.Rules, items []api.Bookmark, u string) (api.Bookmark, bool) {
+	if u == "" {
+		return api.Bookmark{}, false
+	}
+	key := norm.Key(u)
+	for _, b := range items {
+		if norm.Key(b.URL) == key {
+			return b, true
+		}
+	}
+	return api.Bookmark{}, false
+}
+
+// mergeInto adds req's tags to the existing bookmark b with --update, else refuses.
+func mergeInto(base string, opts addOptions, b api.Bookmark, req api.CreateBookmarkRequest) (api.Bookmark, string, error) {
+	if !opts.update {
+		return api.Bookmark{}, "", &duplicateError{existing: b}
+	}
+	tags := mergeTags(append([]string(nil), b.Tags...), req.Tags)
+	if len(tags) == len(b.Tags) {
+		return b, "unchanged", nil
+	}
+	bk, err := api.UpdateBookmark(base, b.Hash, api.UpdateBookmarkRequest{Tags: &tags})
+	return bk, "updated", err
+}
+
+// prefill sets the title and description of a manual add that lacks them from the page
+// itself (unless --no-fetch), returning the page's canonical URL.
+func prefill(opts addOptions, req *api.CreateBookmarkRequest) (canonical string, err error) {
+	if req.AI || !opts.fetch || (req.Title != "" && req.Description != "") {
+		return "", nil
+	}
+	m, err := pagemeta.Fetch(req.URL, opts.timeout)
+	if err != nil {
+		return "", err
+	}
+	req.Title = firstNonEmpty(req.Title, m.Title)
+	req.Description = firstNonEmpty(req.Description, m.Description)
+	return m.Canonical, nil
+}
+
 func init() {
 	addCmd.Flags().BoolVar(&addFlagAI, "ai", false, "Use AI to enrich bookmark details")
 	addCmd.Flags().StringVarP(&addFlagTitle, "title", "T", "", "Title (manual mode or override AI)")
 	addCmd.Flags().StringSliceVarP(&addFlagTags, "tag", "t", nil, "Tag(s) (repeat or comma separated)")
 	addCmd.Flags().StringVarP(&addFlagDesc, "description", "d", "", "Description (manual mode or override AI)")
 	addCmd.Flags().StringVarP(&addFlagSourceFile, "source-file", "f", "", "Source file (e.g., inbox.md)")
-	addCmd.Flags().BoolVar(&addFlagJSON, "json", false, "Output created bookmark as JSON")
+	addCmd.Flags().BoolVar(&addFlagPaste, "paste", false, "Take the URL from the clipboard when none is given")
+	addCmd.Flags().BoolVar(&addFlagNoFetch, "no-fetch", false, "Do not read the title and description from the page when adding without --ai")
+	addCmd.Flags().BoolVar(&addFlagForce, "force", false, "Add even if the URL is already bookmarked")
+	addCmd.Flags().BoolVar(&addFlagUpdate, "update", false, "If the URL is already bookmarked, add the new tags to it instead")
+	addCmd.MarkFlagsMutuallyExclusive("force", "update")
+	addCmd.Flags().StringVar(&addFlagFromFile, "from-file", "", "Add every URL found in this file (- for stdin)")
+	addCmd.Flags().IntVar(&addFlagWorkers, "workers", 4, "Bookmarks created at the same time when adding several")
+	addCmd.Flags().Float64Var(&addFlagRate, "rate", 0, "At most this many requests per second when adding several (0 for no limit)")
+	addCmd.Flags().BoolVar(&addFlagJSON, "json", false, "Output created bookmark as JSON (same as --output json)")
 }
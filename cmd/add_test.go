@@ -0,0 +1,107 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/amaterasu/markdex-cli/internal/api"
+	"github.com/amaterasu/markdex-cli/internal/urlnorm"
+)
+
+func TestFindDuplicate(t *testing.T) {
+	items := []api.Bookmark{
+		{Hash: "a", URL: "https://example.com/post"},
+		{Hash: "b", URL: "https://example.com/?p=2"},
+	}
+	tests := []struct {
+		url  string
+		want string
+	}{
+		{"https://example.com/post", "a"},
+		{"http://Example.com/post/?utm_source=x#top", "a"},
+		{"https://www.example.com/post", ""}, // a different host
+		{"https://example.com?p=2", "b"},
+		{"https://example.com/?p=3", ""},
+		{"https://example.com/post/2", ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		b, ok := findDuplicate(urlnorm.Rules{}, items, tt.url)
+		if ok != (tt.want != "") || b.Hash != tt.want {
+			t.Errorf("findDuplicate(%q) = %q, %v; want %q", tt.url, b.Hash, ok, tt.want)
+		}
+	}
+}
+
+func TestCreateOrMerge(t *testing.T) {
+	var calls []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls = append(calls, r.Method+" "+r.URL.Path)
+		switch {
+		case r.URL.Path == "/moved":
+			fmt.Fprint(w, `<html><head><title>Moved</title><link rel="canonical" href="https://example.com/post"></head></html>`)
+		case r.URL.Path == "/fresh":
+			fmt.Fprint(w, `<html><head><title>Fresh page</title></head></html>`)
+		case r.Method == "POST":
+			var req api.CreateBookmarkRequest
+			json.NewDecoder(r.Body).Decode(&req)
+			json.NewEncoder(w).Encode(api.Bookmark{Hash: "new", Title: req.Title, URL: req.URL, Tags: req.Tags})
+		case r.Method == "PATCH":
+			var req api.UpdateBookmarkRequest
+			json.NewDecoder(r.Body).Decode(&req)
+			json.NewEncoder(w).Encode(api.Bookmark{Hash: strings.TrimPrefix(r.URL.Path, "/api/bookmarks/"), Tags: *req.Tags})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	items := []api.Bookmark{{Hash: "a", Title: "Post", URL: "https://example.com/post", Tags: []string{"go"}}}
+	tests := []struct {
+		name   string
+		url    string
+		tags   []string
+		update bool
+		fetch  bool
+		status string // "" for a duplicateError
+		want   string // hash and tags of the result
+		calls  string
+	}{
+		{name: "new", url: srv.URL + "/fresh", tags: []string{"x"}, status: "added", want: "new x", calls: "POST /api/bookmarks"},
+		{name: "new, filled in from the page", url: srv.URL + "/fresh", fetch: true, status: "added", want: "new", calls: "GET /fresh,POST /api/bookmarks"},
+		{name: "duplicate", url: "http://example.com/post/", tags: []string{"web"}},
+		{name: "duplicate by canonical URL", url: srv.URL + "/moved", fetch: true, calls: "GET /moved"},
+		{name: "update adds tags", url: "https://example.com/post", tags: []string{"GO", "web"}, update: true, status: "updated", want: "a go,web", calls: "PATCH /api/bookmarks/a"},
+		{name: "update without new tags", url: "https://example.com/post", tags: []string{"Go"}, update: true, status: "unchanged", want: "a go"},
+	}
+	for _, tt := range tests {
+		calls = nil
+		opts := addOptions{update: tt.update, fetch: tt.fetch, timeout: 5 * time.Second}
+		req := api.CreateBookmarkRequest{URL: tt.url, Tags: tt.tags}
+		bk, status, err := createOrMerge(srv.URL, opts, items, req, io.Discard)
+		if got := strings.Join(calls, ","); got != tt.calls {
+			t.Errorf("%s: requests %q, want %q", tt.name, got, tt.calls)
+		}
+		if tt.status == "" {
+			var dup *duplicateError
+			if !errors.As(err, &dup) || dup.existing.Hash != "a" {
+				t.Errorf("%s: error = %v, want a duplicate of a", tt.name, err)
+			}
+			continue
+		}
+		if err != nil || status != tt.status {
+			t.Errorf("%s: createOrMerge = %q, %v; want %q", tt.name, status, err, tt.status)
+			continue
+		}
+		if got := strings.TrimSpace(bk.Hash + " " + strings.Join(bk.Tags, ",")); got != tt.want {
+			t.Errorf("%s: bookmark %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,195 @@
+package cmd
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/amaterasu/markdex-cli/internal/api"
+	"github.com/amaterasu/markdex-cli/internal/config"
+	"github.com/amaterasu/markdex-cli/internal/output"
+	"github.com/amaterasu/markdex-cli/internal/urlnorm"
+)
+
+// addResult reports what happened to one URL of a batch add.
+type addResult struct {
+	URL    string `json:"url" yaml:"url"`
+	Status string `json:"status" yaml:"status"` // added, updated, unchanged, exists or failed
+	Hash   string `json:"hash,omitempty" yaml:"hash,omitempty"`
+	Title  string `json:"title,omitempty" yaml:"title,omitempty"`
+	Error  string `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+// addBatch adds every URL read from stdin or --from-file with a pool of workers.
+func addBatch(cfg *config.Config, args []string) error {
+	if len(args) == 1 && addFlagFromFile != "" {
+		return errors.New("give either - or --from-file, not both")
+	}
+	base := firstNonEmpty(apiBase, cfg.APIBase)
+	if base == "" {
+		return fmt.Errorf("API base not set (use markdex config set --api <url>)")
+	}
+	var in io.Reader = os.Stdin
+	if addFlagFromFile != "" && addFlagFromFile != "-" {
+		f, err := os.Open(addFlagFromFile)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		in = f
+	}
+	opts := newAddOptions(cfg)
+	urls, err := extractURLs(in, opts.norm)
+	if err != nil {
+		return err
+	}
+	if len(urls) == 0 {
+		return errors.New("no URLs found in the input")
+	}
+	proj, err := currentProject()
+	if err != nil {
+		return err
+	}
+	// everything the workers read is prepared here; they only share the results slice
+	reqs := make([]api.CreateBookmarkRequest, len(urls))
+	for i, u := range urls {
+		reqs[i] = addRequest(opts, proj, u)
+	}
+
+	existing := existingBookmarks(base, cfg)
+	results := make([]addResult, len(reqs))
+	progress := newAddProgress(len(reqs))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < max(addFlagWorkers, 1); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				bk, status, err := createOrMerge(base, opts, existing, reqs[i], io.Discard)
+				r := newAddResult(reqs[i].URL, bk, status, err)
+				results[i] = r
+				progress.step(r.Error != "")
+			}
+		}()
+	}
+	var tick <-chan time.Time
+	if addFlagRate > 0 {
+		t := time.NewTicker(time.Duration(float64(time.Second) / addFlagRate))
+		defer t.Stop()
+		tick = t.C
+	}
+	for i := range reqs {
+		if tick != nil && i > 0 {
+			<-tick
+		}
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+	progress.finish()
+
+	failed := 0
+	for _, r := range results {
+		if r.Error != "" {
+			failed++
+		}
+	}
+	if failed < len(results) {
+		invalidateCache(cfg)
+	}
+	if addFlagJSON {
+		outFormat = output.JSON
+	}
+	t := output.Tabular{Header: []string{"STATUS", "HASH", "TITLE", "URL", "ERROR"}}
+	for _, r := range results {
+		t.Rows = append(t.Rows, []string{r.Status, r.Hash, r.Title, r.URL, r.Error})
+	}
+	if err := printRows(results, t); err != nil {
+		return err
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d URLs failed", failed, len(results))
+	}
+	return nil
+}
+
+// newAddResult reports the outcome of createOrMerge for url. A URL that is already
+// bookmarked (without --update) is not a failure: it is reported as exists, with the
+// bookmark it is.
+func newAddResult(url string, bk api.Bookmark, status string, err error) addResult {
+	var dup *duplicateError
+	switch {
+	case errors.As(err, &dup):
+		return addResult{URL: url, Status: "exists", Hash: dup.existing.Hash, Title: dup.existing.Title}
+	case err != nil:
+		return addResult{URL: url, Status: "failed", Error: err.Error()}
+	}
+	return addResult{URL: url, Status: status, Hash: bk.Hash, Title: bk.Title}
+}
+
+// urlPattern finds http(s) URLs in plain text and markdown (where a link's URL ends at ")").
+var urlPattern = regexp.MustCompile(`https?://[^\s<>"'()\[\]` + "`" + `]+`)
+
+// extractURLs returns the URLs in r that are distinct pages by norm, in order of first appearance.
+func extractURLs(r io.Reader, norm urlnorm.Rules) ([]string, error) {
+	var out []string
+	seen := map[string]bool{}
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 64*1024), 1024*1024)
+	for sc.Scan() {
+		for _, u := range urlPattern.FindAllString(sc.Text(), -1) {
+			u = strings.TrimRight(u, ".,;:!?*_")
+			if k := norm.Key(u); !seen[k] {
+				seen[k] = true
+				out = append(out, u)
+			}
+		}
+	}
+	return out, sc.Err()
+}
+
+// addProgress shows "Adding n/total" on stderr while a batch runs, when it is a terminal.
+type addProgress struct {
+	mu                  sync.Mutex
+	tty                 bool
+	total, done, failed int
+}
+
+func newAddProgress(total int) *addProgress {
+	p := &addProgress{tty: output.DetectTerm(os.Stderr).TTY, total: total}
+	p.draw()
+	return p
+}
+
+func (p *addProgress) step(failed bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.done++
+	if failed {
+		p.failed++
+	}
+	p.draw()
+}
+
+func (p *addProgress) draw() {
+	if !p.tty {
+		return
+	}
+	fmt.Fprintf(os.Stderr, "\r\033[KAdding %d/%d", p.done, p.total)
+	if p.failed > 0 {
+		fmt.Fprintf(os.Stderr, " (%d failed)", p.failed)
+	}
+}
+
+func (p *addProgress) finish() {
+	if p.tty {
+		fmt.Fprint(os.Stderr, "\r\033[K")
+	}
+}
@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/amaterasu/markdex-cli/internal/api"
+	"github.com/amaterasu/markdex-cli/internal/urlnorm"
+)
+
+func TestExtractURLs(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"one per line", "https://a.example/x\nhttps://b.example/y\n", "https://a.example/x https://b.example/y"},
+		{"markdown", "- [A](https://a.example/x), see https://b.example/y.\n", "https://a.example/x https://b.example/y"},
+		{"none", "nothing here\nftp://a.example/\n", ""},
+		{"same URL twice", "https://a.example/x\nhttps://a.example/x\n", "https://a.example/x"},
+		{
+			name: "same page twice",
+			in:   "http://A.example/x/?utm_source=feed\nhttps://a.example/x#top\nhttps://a.example/x\n",
+			want: "http://A.example/x/?utm_source=feed",
+		},
+		{"different pages", "https://a.example/x\nhttps://a.example/x?page=2\n", "https://a.example/x https://a.example/x?page=2"},
+	}
+	for _, tt := range tests {
+		got, err := extractURLs(strings.NewReader(tt.in), urlnorm.Rules{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if strings.Join(got, " ") != tt.want {
+			t.Errorf("%s: extractURLs = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestNewAddResult(t *testing.T) {
+	bk := api.Bookmark{Hash: "abc123", Title: "A"}
+	tests := []struct {
+		name   string
+		status string
+		err    error
+		want   addResult
+	}{
+		{"added", "added", nil, addResult{URL: "u", Status: "added", Hash: "abc123", Title: "A"}},
+		{"updated", "updated", nil, addResult{URL: "u", Status: "updated", Hash: "abc123", Title: "A"}},
+		{"already bookmarked", "", &duplicateError{existing: api.Bookmark{Hash: "def456", Title: "Old"}},
+			addResult{URL: "u", Status: "exists", Hash: "def456", Title: "Old"}},
+		{"failed", "", errors.New("status 500"), addResult{URL: "u", Status: "failed", Error: "status 500"}},
+	}
+	for _, tt := range tests {
+		if got := newAddResult("u", bk, tt.status, tt.err); got != tt.want {
+			t.Errorf("%s: newAddResult = %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -1,14 +1,26 @@
 package cmd
 
 import (
+	"bufio"
+	"errors"
 	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
 
+	"github.com/amaterasu/markdex-cli/internal/clipboard"
 	"github.com/amaterasu/markdex-cli/internal/config"
+	"github.com/amaterasu/markdex-cli/internal/output"
 	"github.com/spf13/cobra"
 )
 
 var cfgAPI string
 var cfgUser string
+var cfgListDefaults bool
 
 var configCmd = &cobra.Command{
 	Use:   "config",
@@ -16,17 +28,57 @@ var configCmd = &cobra.Command{
 }
 
 var configSetCmd = &cobra.Command{
-	Use:   "set",
+	Use:   "set [<key> <value>]",
 	Short: "Set configuration values",
+	Long:  "Set a configuration value, e.g. 'markdex config set cacheTTL 10m'. Run 'markdex config list --defaults' to see all keys. Named entries use '<table>.<name>', e.g. 'markdex config set templates.short \"{{short .Hash}} {{.URL}}\"'. The --api and --user flags remain as shortcuts for apiBase and userId.",
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) == 0 && (cfgAPI != "" || cfgUser != "") {
+			return nil
+		}
+		return cobra.ExactArgs(2)(cmd, args)
+	},
 	RunE: func(cmd *cobra.Command, args []string) error {
-		c, _ := config.Load()
+		if len(args) == 2 {
+			if err := config.Set(args[0], args[1]); err != nil {
+				return err
+			}
+		}
 		if cfgAPI != "" {
-			c.APIBase = cfgAPI
+			if err := config.Set("apiBase", cfgAPI); err != nil {
+				return err
+			}
 		}
 		if cfgUser != "" {
-			c.UserID = cfgUser
+			if err := config.Set("userId", cfgUser); err != nil {
+				return err
+			}
 		}
-		if err := config.Save(c); err != nil {
+		fmt.Println("Saved config")
+		return nil
+	},
+}
+
+var configGetCmd = &cobra.Command{
+	Use:   "get <key>",
+	Short: "Print the effective value of a configuration key",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		c, _ := config.Load()
+		v, err := config.Get(c, args[0])
+		if err != nil {
+			return err
+		}
+		fmt.Println(v)
+		return nil
+	},
+}
+
+var configUnsetCmd = &cobra.Command{
+	Use:   "unset <key>",
+	Short: "Remove a configuration key so its default applies",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := config.Unset(args[0]); err != nil {
 			return err
 		}
 		fmt.Println("Saved config")
@@ -34,17 +86,104 @@ var configSetCmd = &cobra.Command{
 	},
 }
 
-var configShowCmd = &cobra.Command{
-	Use:   "show",
-	Short: "Show current configuration",
+var configListCmd = &cobra.Command{
+	Use:     "list",
+	Aliases: []string{"show"},
+	Short:   "Show current configuration",
 	RunE: func(cmd *cobra.Command, args []string) error {
-		c, _ := config.Load()
-		fmt.Printf("apiBase: %s\n", c.APIBase)
-		u := c.UserID
-		if u == "" {
-			u = "default"
+		c, err := config.Load()
+		if err != nil && fileExists(config.Path()) {
+			fmt.Fprintf(os.Stderr, "warning: %v\n", err)
+		} else if err == nil {
+			warnUnknownKeys(config.Path())
+		}
+		if cfgListDefaults {
+			w := bufio.NewWriter(os.Stdout)
+			for _, k := range config.Schema {
+				def := k.Default
+				if def == "" {
+					def = `""`
+				}
+				fmt.Fprintf(w, "%-12s %-9s default %-8s %s", k.Name, k.Type, def, k.Description)
+				if len(k.Choices) > 0 {
+					fmt.Fprintf(w, " (%s)", strings.Join(k.Choices, "|"))
+				}
+				fmt.Fprintln(w)
+			}
+			for _, t := range config.Tables {
+				fmt.Fprintf(w, "%-12s %-9s %-16s %s\n", t.Name+".<name>", "table", "", t.Description)
+			}
+			return w.Flush()
+		}
+		for _, k := range config.Schema {
+			fmt.Printf("%s: %s\n", k.Name, k.Value(c))
+		}
+		for _, t := range config.Tables {
+			entries := t.Entries(c)
+			names := make([]string, 0, len(entries))
+			for name := range entries {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			for _, name := range names {
+				fmt.Printf("%s.%s: %s\n", t.Name, name, entries[name])
+			}
 		}
-		fmt.Printf("userId: %s\n", u)
+		return nil
+	},
+}
+
+var configEditCmd = &cobra.Command{
+	Use:   "edit",
+	Short: "Open config.toml in $EDITOR and validate it on save",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		path := config.Path()
+		tmp, err := os.CreateTemp("", "markdex-config-*.toml")
+		if err != nil {
+			return err
+		}
+		defer os.Remove(tmp.Name())
+		if src, err := os.Open(path); err == nil {
+			_, err = io.Copy(tmp, src)
+			src.Close()
+			if err != nil {
+				tmp.Close()
+				return err
+			}
+		}
+		if err := tmp.Close(); err != nil {
+			return err
+		}
+
+		for {
+			if err := runEditor(tmp.Name()); err != nil {
+				return err
+			}
+			verr := config.ValidateFile(tmp.Name())
+			if verr == nil {
+				// unknown keys are allowed but are most likely typos
+				if !warnUnknownKeys(tmp.Name()) || !confirmDefault("Edit again?", false) {
+					break
+				}
+				continue
+			}
+			fmt.Fprintf(os.Stderr, "invalid config: %v\n", verr)
+			if !confirmDefault("Edit again?", true) {
+				return errors.New("config not saved")
+			}
+		}
+
+		b, err := os.ReadFile(tmp.Name())
+		if err != nil {
+			return err
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(path, b, 0o644); err != nil {
+			return err
+		}
+		fmt.Println("Saved config")
 		return nil
 	},
 }
@@ -59,9 +198,56 @@ var configPathCmd = &cobra.Command{
 }
 
 func init() {
+	// values defined outside the config package
+	config.SetChoices("clipboard", clipboard.Names)
+	config.SetValidator("templates", func(v string) error { _, err := output.ParseTemplate(v); return err })
 	configCmd.AddCommand(configSetCmd)
-	configCmd.AddCommand(configShowCmd)
+	configCmd.AddCommand(configGetCmd)
+	configCmd.AddCommand(configUnsetCmd)
+	configCmd.AddCommand(configListCmd)
+	configCmd.AddCommand(configEditCmd)
 	configCmd.AddCommand(configPathCmd)
 	configSetCmd.Flags().StringVar(&cfgAPI, "api", "", "API base URL")
 	configSetCmd.Flags().StringVar(&cfgUser, "user", "", "Default user id (e.g., workspace or profile)")
+	configListCmd.Flags().BoolVar(&cfgListDefaults, "defaults", false, "List all keys with their types, defaults and descriptions")
+}
+
+// runEditor opens path in $VISUAL / $EDITOR (falling back to a platform default) and waits for it to exit.
+func runEditor(path string) error {
+	editor := firstNonEmpty(os.Getenv("VISUAL"), os.Getenv("EDITOR"))
+	if editor == "" {
+		if runtime.GOOS == "windows" {
+			editor = "notepad"
+		} else {
+			editor = "vi"
+		}
+	}
+	// $EDITOR may carry arguments, e.g. "code --wait".
+	parts := strings.Fields(editor)
+	c := exec.Command(parts[0], append(parts[1:], path)...)
+	c.Stdin = os.Stdin
+	c.Stdout = os.Stdout
+	c.Stderr = os.Stderr
+	if err := c.Run(); err != nil {
+		return fmt.Errorf("editor %s: %w", parts[0], err)
+	}
+	return nil
+}
+
+// warnUnknownKeys prints a warning for each key in the config file at path that markdex
+// does not read, and reports whether there were any.
+func warnUnknownKeys(path string) bool {
+	keys, err := config.UnknownKeys(path)
+	if err != nil {
+		return false
+	}
+	for _, k := range keys {
+		fmt.Fprintf(os.Stderr, "warning: unknown config key %q (see markdex config list --defaults)\n", k)
+	}
+	return len(keys) > 0
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
 }
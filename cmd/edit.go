@@ -0,0 +1,200 @@
+package cmd
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+
+	"github.com/amaterasu/markdex-cli/internal/api"
+	"github.com/amaterasu/markdex-cli/internal/cache"
+	"github.com/amaterasu/markdex-cli/internal/config"
+)
+
+var rmFlagYes bool
+
+// editable is the part of a bookmark shown in the editor by markdex edit.
+type editable struct {
+	Title       string   `yaml:"title"`
+	URL         string   `yaml:"url"`
+	Tags        []string `yaml:"tags,flow"`
+	Section     string   `yaml:"section"`
+	SourceFile  string   `yaml:"source_file"`
+	Description string   `yaml:"description"`
+}
+
+var editCmd = &cobra.Command{
+	Use:   "edit <index | hash-prefix | query...>",
+	Short: "Edit a bookmark in $EDITOR",
+	Long:  "Edit a bookmark's fields as YAML in $VISUAL or $EDITOR; the changed fields are sent to the server when you save and close. The bookmark is chosen like with open: a position in the last listing, a hash prefix or a query matching one bookmark.",
+	Args:  cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, _ := config.Load()
+		base := firstNonEmpty(apiBase, cfg.APIBase)
+		if base == "" {
+			return fmt.Errorf("API base not set")
+		}
+		matches, err := resolveBookmarks(base, cfg, args)
+		if err != nil {
+			return err
+		}
+		if len(matches) != 1 {
+			return errors.New("edit one bookmark at a time")
+		}
+		b := matches[0]
+		before := editable{Title: b.Title, URL: b.URL, Tags: b.Tags, Section: b.Section, SourceFile: b.SourceFile, Description: b.Description}
+		after, err := editBookmark(b.Hash, before)
+		if err != nil {
+			return err
+		}
+		req, changed := bookmarkChanges(before, after)
+		if !changed {
+			fmt.Println("No changes")
+			return nil
+		}
+		updated, err := api.UpdateBookmark(base, b.Hash, req)
+		if err != nil {
+			return err
+		}
+		invalidateCache(cfg)
+		fmt.Printf("Updated %s (%s)\n", firstNonEmpty(updated.Title, after.Title), b.Hash)
+		return nil
+	},
+}
+
+var rmCmd = &cobra.Command{
+	Use:     "rm <index... | hash-prefix... | query...>",
+	Aliases: []string{"delete"},
+	Short:   "Delete bookmarks (asks for confirmation)",
+	Long:    "Delete bookmarks chosen like with open: positions in the last listing, hash prefixes or a query matching one bookmark. Asks before deleting unless --yes is given.",
+	Args:    cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, _ := config.Load()
+		base := firstNonEmpty(apiBase, cfg.APIBase)
+		if base == "" {
+			return fmt.Errorf("API base not set")
+		}
+		matches, err := resolveBookmarks(base, cfg, args)
+		if err != nil {
+			return err
+		}
+		if !rmFlagYes {
+			for _, b := range matches {
+				fmt.Fprintf(os.Stderr, "  %s  %s\n", b.Hash, b.Title)
+			}
+			if !confirm(fmt.Sprintf("Delete %d bookmark(s)?", len(matches))) {
+				fmt.Println("Nothing deleted")
+				return nil
+			}
+		}
+		for _, b := range matches {
+			if err := api.DeleteBookmark(base, b.Hash); err != nil {
+				return fmt.Errorf("%s: %w", b.Hash, err)
+			}
+			fmt.Printf("Deleted %s (%s)\n", b.Title, b.Hash)
+		}
+		invalidateCache(cfg)
+		return nil
+	},
+}
+
+func init() {
+	rmCmd.Flags().BoolVarP(&rmFlagYes, "yes", "y", false, "Delete without asking")
+	rootCmd.AddCommand(editCmd)
+	rootCmd.AddCommand(rmCmd)
+}
+
+// editBookmark lets the user edit e in their editor until it parses, returning the result.
+func editBookmark(hash string, e editable) (editable, error) {
+	tmp, err := os.CreateTemp("", "markdex-bookmark-*.yaml")
+	if err != nil {
+		return e, err
+	}
+	defer os.Remove(tmp.Name())
+	fmt.Fprintf(tmp, "# Bookmark %s. Save and close to apply; leave unchanged to cancel.\n", hash)
+	enc := yaml.NewEncoder(tmp)
+	enc.SetIndent(2)
+	if err := enc.Encode(e); err != nil {
+		tmp.Close()
+		return e, err
+	}
+	if err := tmp.Close(); err != nil {
+		return e, err
+	}
+	for {
+		if err := runEditor(tmp.Name()); err != nil {
+			return e, err
+		}
+		b, err := os.ReadFile(tmp.Name())
+		if err != nil {
+			return e, err
+		}
+		var out editable
+		err = yaml.Unmarshal(b, &out)
+		if err == nil && strings.TrimSpace(out.URL) == "" {
+			err = errors.New("url must not be empty")
+		}
+		if err == nil {
+			return out, nil
+		}
+		fmt.Fprintf(os.Stderr, "invalid bookmark: %v\n", err)
+		if !confirmDefault("Edit again?", true) {
+			return e, errors.New("bookmark not changed")
+		}
+	}
+}
+
+// bookmarkChanges builds an update with the fields that differ between before and after.
+func bookmarkChanges(before, after editable) (api.UpdateBookmarkRequest, bool) {
+	var req api.UpdateBookmarkRequest
+	changed := false
+	str := func(dst **string, old, new string) {
+		if new = strings.TrimSpace(new); new != old {
+			*dst = &new
+			changed = true
+		}
+	}
+	str(&req.Title, before.Title, after.Title)
+	str(&req.URL, before.URL, after.URL)
+	str(&req.Section, before.Section, after.Section)
+	str(&req.SourceFile, before.SourceFile, after.SourceFile)
+	str(&req.Description, before.Description, strings.TrimRight(after.Description, "\n"))
+	if tags := mergeTags(nil, after.Tags); !slices.Equal(tags, before.Tags) && !(len(tags) == 0 && len(before.Tags) == 0) {
+		if tags == nil {
+			tags = []string{}
+		}
+		req.Tags = &tags
+		changed = true
+	}
+	return req, changed
+}
+
+// invalidateCache removes the bookmark cache so the next list or pick sees server changes.
+func invalidateCache(cfg *config.Config) {
+	_ = os.Remove(cache.New(cfg.CacheTTL).Path)
+}
+
+// confirm asks a yes/no question on stderr; the default is no.
+func confirm(question string) bool { return confirmDefault(question, false) }
+
+// confirmDefault asks a yes/no question on stderr, reading the answer from stdin.
+func confirmDefault(question string, def bool) bool {
+	hint := "[y/N]"
+	if def {
+		hint = "[Y/n]"
+	}
+	fmt.Fprintf(os.Stderr, "%s %s ", question, hint)
+	answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return true
+	case "n", "no":
+		return false
+	}
+	return def
+}
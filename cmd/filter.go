@@ -0,0 +1,125 @@
+package cmd
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/spf13/pflag"
+
+	"github.com/amaterasu/markdex-cli/internal/api"
+	"github.com/amaterasu/markdex-cli/internal/cache"
+	"github.com/amaterasu/markdex-cli/internal/config"
+	"github.com/amaterasu/markdex-cli/internal/filter"
+	"github.com/amaterasu/markdex-cli/internal/query"
+)
+
+// tagFlags holds the tag filter flags shared by list and pick.
+type tagFlags struct {
+	all      []string
+	any      []string
+	exclude  []string
+	untagged bool
+}
+
+func (f *tagFlags) register(fs *pflag.FlagSet) {
+	fs.StringSliceVarP(&f.all, "tag", "t", nil, "Require tag (repeat to require all)")
+	fs.StringSliceVar(&f.any, "any-tag", nil, "Require at least one of these tags (repeat or comma separated)")
+	fs.StringSliceVar(&f.exclude, "exclude-tag", nil, "Skip bookmarks with this tag (repeat or comma separated)")
+	fs.BoolVar(&f.untagged, "untagged", false, "Only bookmarks without tags")
+}
+
+// filter builds the tag filter. A tag containing ':' almost always means a negated query
+// term such as -tag:old was parsed as the -t flag, so it is rejected with a hint.
+func (f *tagFlags) filter() (filter.Tags, error) {
+	for _, t := range f.all {
+		if strings.Contains(t, ":") {
+			return filter.Tags{}, fmt.Errorf("tag %q looks like part of a query; put negated query terms after --, e.g. markdex list -- tag:go -tag:old", t)
+		}
+	}
+	return filter.Tags{All: f.all, Any: f.any, Exclude: f.exclude, Untagged: f.untagged}, nil
+}
+
+// queryBookmarks loads bookmarks for list and pick. Without a search the full collection
+// comes from the cache (or the API, refreshing the cache) and tags are filtered locally;
+// with a search the API is queried and the tag filter is sent along when the server
+// understands it. The tag filter is always re-applied client-side.
+func queryBookmarks(base string, cfg *config.Config, search string, tags filter.Tags, noCache bool) ([]api.Bookmark, error) {
+	c := cache.New(cfg.CacheTTL)
+	if search == "" && !noCache {
+		if items, ok := c.Read(); ok {
+			return tags.Apply(items), nil
+		}
+	}
+	q := url.Values{}
+	if search != "" {
+		q.Set("q", search)
+	}
+	if !tags.Empty() && (tags.Simple() || cfg.ServerTagFilters) {
+		tags.Query(q)
+	}
+	items, err := api.FetchBookmarks(base, q)
+	if err != nil {
+		return nil, err
+	}
+	if len(q) == 0 {
+		c.Write(items)
+	}
+	return tags.Apply(items), nil
+}
+
+// parseQuery parses positional arguments as a query (see package query). Arguments of the
+// form @name are replaced by the saved search of that name; the first saved search used
+// is returned so its sort and output defaults can apply.
+func parseQuery(cfg *config.Config, args []string) (query.Query, *config.SavedSearch, error) {
+	text, saved, err := expandQuery(cfg, args)
+	if err != nil {
+		return query.Query{}, nil, err
+	}
+	q, err := query.Parse(text)
+	return q, saved, err
+}
+
+// expandQuery joins args into a query string, expanding @name saved searches.
+func expandQuery(cfg *config.Config, args []string) (string, *config.SavedSearch, error) {
+	var saved *config.SavedSearch
+	parts := make([]string, 0, len(args))
+	for _, a := range args {
+		if name, ok := strings.CutPrefix(a, "@"); ok && name != "" {
+			s, ok := cfg.Saved[strings.ToLower(name)]
+			if !ok {
+				return "", nil, fmt.Errorf("no saved search named %q (see markdex saved list)", name)
+			}
+			if saved == nil {
+				saved = &s
+			}
+			parts = append(parts, s.Query)
+			continue
+		}
+		parts = append(parts, joinQuery([]string{a}))
+	}
+	return strings.Join(parts, " "), saved, nil
+}
+
+// joinQuery joins shell arguments back into one query string. Arguments that contained
+// spaces were quoted on the command line, so they are re-quoted as phrases (or as
+// quoted field values, e.g. in:"my notes.md").
+func joinQuery(args []string) string {
+	parts := make([]string, 0, len(args))
+	for _, a := range args {
+		if strings.ContainsAny(a, " \t") && !strings.Contains(a, `"`) {
+			neg := ""
+			if strings.HasPrefix(a, "-") {
+				neg, a = "-", a[1:]
+			}
+			if field, value, ok := strings.Cut(a, ":"); ok && !strings.ContainsAny(field, " \t") {
+				a = field + `:"` + value + `"`
+			} else {
+				a = `"` + a + `"`
+			}
+			a = neg + a
+		}
+		parts = append(parts, a)
+	}
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/amaterasu/markdex-cli/internal/config"
+)
+
+func TestExpandQuery(t *testing.T) {
+	cfg := &config.Config{Saved: map[string]config.SavedSearch{
+		"work":  {Query: "tag:work -tag:old", Sort: "usage"},
+		"later": {Query: "tag:later"},
+	}}
+	tests := []struct {
+		args  []string
+		want  string
+		saved string // query of the saved search returned
+		err   bool
+	}{
+		{args: nil, want: ""},
+		{args: []string{"go", "tag:web"}, want: "go tag:web"},
+		{args: []string{"@work"}, want: "tag:work -tag:old", saved: "tag:work -tag:old"},
+		{args: []string{"@Later", "go", "@work"}, want: "tag:later go tag:work -tag:old", saved: "tag:later"},
+		{args: []string{"@"}, want: "@"},
+		{args: []string{"me@example.com"}, want: "me@example.com"},
+		{args: []string{"@nope"}, err: true},
+		{args: []string{"design doc", "in:my notes.md", "-tag:old stuff"}, want: `"design doc" in:"my notes.md" -tag:"old stuff"`},
+	}
+	for _, tt := range tests {
+		got, saved, err := expandQuery(cfg, tt.args)
+		if (err != nil) != tt.err {
+			t.Errorf("expandQuery(%q) error = %v", tt.args, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("expandQuery(%q) = %q, want %q", tt.args, got, tt.want)
+		}
+		q := ""
+		if saved != nil {
+			q = saved.Query
+		}
+		if q != tt.saved {
+			t.Errorf("expandQuery(%q) saved search = %q, want %q", tt.args, q, tt.saved)
+		}
+	}
+}
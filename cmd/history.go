@@ -0,0 +1,102 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/amaterasu/markdex-cli/internal/history"
+	"github.com/amaterasu/markdex-cli/internal/output"
+)
+
+var (
+	historyFlagSince string
+	historyFlagLimit int
+	historyFlagJSON  bool
+)
+
+var historyCmd = &cobra.Command{
+	Use:   "history",
+	Short: "Show recently opened and copied bookmarks, newest first",
+	Long:  "Show the local history of bookmarks used by open, pick and copy, newest first. It is kept in $XDG_STATE_HOME/markdex/history.jsonl (~/.local/state by default) and also drives --sort frecency.",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		entries, err := history.Load()
+		if err != nil {
+			return err
+		}
+		var since time.Time
+		if historyFlagSince != "" {
+			d, err := parseAge(historyFlagSince)
+			if err != nil {
+				return fmt.Errorf("--since: %w", err)
+			}
+			since = time.Now().Add(-d)
+		}
+		rows := make([]history.Entry, 0, len(entries))
+		for i := len(entries) - 1; i >= 0; i-- {
+			if entries[i].Time.Before(since) {
+				continue
+			}
+			rows = append(rows, entries[i])
+			if historyFlagLimit > 0 && len(rows) == historyFlagLimit {
+				break
+			}
+		}
+		if historyFlagJSON {
+			outFormat = output.JSON
+		}
+		if len(rows) == 0 && outFormat == output.Table && outTemplate == nil {
+			infof("No history yet.")
+			return nil
+		}
+		t := output.Tabular{Header: []string{"TIME", "HASH", "COMMAND", "TITLE", "URL"}}
+		for _, e := range rows {
+			t.Rows = append(t.Rows, []string{e.Time.Local().Format("2006-01-02 15:04"), e.Hash, e.Command, e.Title, e.URL})
+		}
+		return printRows(rows, t)
+	},
+}
+
+var historyClearCmd = &cobra.Command{
+	Use:   "clear",
+	Short: "Delete the history (this also resets frecency ranking)",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := history.Clear(); err != nil {
+			return err
+		}
+		fmt.Println("History cleared")
+		return nil
+	},
+}
+
+// parseAge parses a duration such as 30m or 12h, also accepting days (2d) and weeks (1w).
+func parseAge(s string) (time.Duration, error) {
+	s = strings.TrimSpace(s)
+	for suffix, unit := range map[string]time.Duration{"d": 24 * time.Hour, "w": 7 * 24 * time.Hour} {
+		if n, ok := strings.CutSuffix(s, suffix); ok {
+			v, err := strconv.ParseFloat(n, 64)
+			if err != nil || v < 0 {
+				return 0, fmt.Errorf("%q is not a duration (e.g. 3h, 2d, 1w)", s)
+			}
+			return time.Duration(v * float64(unit)), nil
+		}
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("%q is not a duration (e.g. 3h, 2d, 1w)", s)
+	}
+	return d, nil
+}
+
+func init() {
+	historyCmd.Flags().StringVar(&historyFlagSince, "since", "", "Only entries newer than this (e.g. 3h, 2d, 1w)")
+	historyCmd.Flags().IntVarP(&historyFlagLimit, "limit", "n", 0, "Show at most this many entries")
+	historyCmd.Flags().BoolVar(&historyFlagJSON, "json", false, "Output JSON (same as --output json)")
+	historyCmd.AddCommand(historyClearCmd)
+	rootCmd.AddCommand(historyCmd)
+}
@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/amaterasu/markdex-cli/internal/history"
+)
+
+func TestParseAge(t *testing.T) {
+	tests := []struct {
+		in   string
+		want time.Duration
+		err  bool
+	}{
+		{in: "30m", want: 30 * time.Minute},
+		{in: " 12h ", want: 12 * time.Hour},
+		{in: "2d", want: 48 * time.Hour},
+		{in: "1.5d", want: 36 * time.Hour},
+		{in: "1w", want: 7 * 24 * time.Hour},
+		{in: "0d", want: 0},
+		{in: "", err: true},
+		{in: "d", err: true},
+		{in: "-1d", err: true},
+		{in: "-3h", err: true},
+		{in: "soon", err: true},
+	}
+	for _, tt := range tests {
+		got, err := parseAge(tt.in)
+		if (err != nil) != tt.err || got != tt.want {
+			t.Errorf("parseAge(%q) = %v, %v; want %v", tt.in, got, err, tt.want)
+		}
+	}
+}
+
+func TestLastOpened(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[{"hash":"abc","title":"Renamed","url":"https://a.example/new"}]`)
+	}))
+	defer srv.Close()
+	down := httptest.NewServer(http.NotFoundHandler())
+	down.Close()
+
+	if _, err := lastOpened(srv.URL); err == nil {
+		t.Error("lastOpened with no history: no error")
+	}
+	history.Record(history.Entry{Hash: "old", Title: "Old", URL: "https://old.example/"})
+	history.Record(history.Entry{Hash: "abc", Title: "Title", URL: "https://a.example/"})
+
+	tests := []struct {
+		name string
+		base string
+		want string
+	}{
+		{"current data from the server", srv.URL, "Renamed https://a.example/new"},
+		{"server down: as recorded", down.URL, "Title https://a.example/"},
+	}
+	for _, tt := range tests {
+		got, err := lastOpened(tt.base)
+		if err != nil || len(got) != 1 || got[0].Title+" "+got[0].URL != tt.want {
+			t.Errorf("%s: lastOpened = %+v, %v; want %s", tt.name, got, err, tt.want)
+		}
+	}
+
+	history.Record(history.Entry{Hash: "gone", Title: "Deleted", URL: "https://gone.example/"})
+	if got, err := lastOpened(srv.URL); err != nil || len(got) != 1 || got[0].Hash != "gone" || got[0].URL != "https://gone.example/" {
+		t.Errorf("deleted on the server: lastOpened = %+v, %v", got, err)
+	}
+}
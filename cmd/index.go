@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/amaterasu/markdex-cli/internal/api"
+	"github.com/amaterasu/markdex-cli/internal/cache"
+	"github.com/amaterasu/markdex-cli/internal/config"
+)
+
+// maxListingAge is how long the last listing stays valid for markdex open <n>.
+const maxListingAge = time.Hour
+
+var indexSpec = regexp.MustCompile(`^\d+(-\d+)?(,\d+(-\d+)?)*,?$`)
+
+// listingProfile identifies the API and user a listing belongs to.
+func listingProfile(base string, cfg *config.Config) string {
+	return base + " " + cfg.UserID
+}
+
+// rememberListing stores items, in display order, as the last listing.
+func rememberListing(base string, cfg *config.Config, items []api.Bookmark) {
+	cache.WriteListing(listingProfile(base, cfg), items)
+}
+
+// isIndexSpec reports whether every argument is a 1-based index or range such as 3, 1-3 or 1-3,5.
+func isIndexSpec(args []string) bool {
+	for _, a := range args {
+		if !indexSpec.MatchString(a) {
+			return false
+		}
+	}
+	return len(args) > 0
+}
+
+// parseIndexes expands index specs into 1-based positions in order, without duplicates.
+// Positions must lie within 1..n.
+func parseIndexes(args []string, n int) ([]int, error) {
+	var out []int
+	seen := map[int]bool{}
+	for _, a := range args {
+		for _, part := range strings.Split(strings.TrimSuffix(a, ","), ",") {
+			lo, hi, isRange := strings.Cut(part, "-")
+			from, _ := strconv.Atoi(lo)
+			to := from
+			if isRange {
+				to, _ = strconv.Atoi(hi)
+			}
+			if from > to {
+				return nil, fmt.Errorf("invalid range %s", part)
+			}
+			if from < 1 || to > n {
+				return nil, fmt.Errorf("index %s out of range (the last listing has %d entries)", part, n)
+			}
+			for i := from; i <= to; i++ {
+				if !seen[i] {
+					seen[i] = true
+					out = append(out, i)
+				}
+			}
+		}
+	}
+	return out, nil
+}
+
+// fromListing returns the bookmarks at the given index specs of the last listing.
+func fromListing(base string, cfg *config.Config, args []string) ([]api.Bookmark, error) {
+	l, ok := cache.ReadListing()
+	if !ok {
+		return nil, errors.New("no previous listing to pick an index from (run markdex list first)")
+	}
+	if want := listingProfile(base, cfg); l.Profile != want {
+		return nil, fmt.Errorf("the last listing was made for %q, not %q (run markdex list again)", l.Profile, want)
+	}
+	if age := l.Age(); age > maxListingAge {
+		return nil, fmt.Errorf("the last listing is too old to pick by index (%s ago; run markdex list again)", age.Round(time.Minute))
+	}
+	idx, err := parseIndexes(args, len(l.Items))
+	if err != nil {
+		return nil, err
+	}
+	items := make([]api.Bookmark, len(idx))
+	for i, n := range idx {
+		items[i] = l.Items[n-1]
+	}
+	return items, nil
+}
@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestIsIndexSpec(t *testing.T) {
+	tests := []struct {
+		args []string
+		want bool
+	}{
+		{[]string{"3"}, true},
+		{[]string{"1-3"}, true},
+		{[]string{"1-3,5,"}, true},
+		{[]string{"1-3", "5"}, true},
+		{[]string{"10,2-4,7-7"}, true},
+		{nil, false},
+		{[]string{""}, false},
+		{[]string{","}, false},
+		{[]string{"1,,2"}, false},
+		{[]string{"-3"}, false},
+		{[]string{"1-"}, false},
+		{[]string{"1-2-3"}, false},
+		{[]string{"abc"}, false},
+		{[]string{"3", "abc"}, false},
+		{[]string{" 3"}, false},
+	}
+	for _, tt := range tests {
+		if got := isIndexSpec(tt.args); got != tt.want {
+			t.Errorf("isIndexSpec(%q) = %v, want %v", tt.args, got, tt.want)
+		}
+	}
+}
+
+func TestParseIndexes(t *testing.T) {
+	tests := []struct {
+		args []string
+		n    int
+		want string
+		err  string
+	}{
+		{args: []string{"3"}, n: 5, want: "[3]"},
+		{args: []string{"1-3,5,"}, n: 5, want: "[1 2 3 5]"},
+		{args: []string{"5,1-3"}, n: 5, want: "[5 1 2 3]"},
+		{args: []string{"2-4", "3", "1-2"}, n: 5, want: "[2 3 4 1]"},
+		{args: []string{"4-4"}, n: 5, want: "[4]"},
+		{args: []string{"1-5"}, n: 5, want: "[1 2 3 4 5]"},
+		{args: []string{"3-1"}, n: 5, err: "invalid range 3-1"},
+		{args: []string{"0"}, n: 5, err: "index 0 out of range (the last listing has 5 entries)"},
+		{args: []string{"1-3,6"}, n: 5, err: "index 6 out of range (the last listing has 5 entries)"},
+		{args: []string{"4-9"}, n: 5, err: "index 4-9 out of range (the last listing has 5 entries)"},
+		{args: []string{"1"}, n: 0, err: "index 1 out of range (the last listing has 0 entries)"},
+		{args: []string{"99999999999999999999"}, n: 5, err: "index 99999999999999999999 out of range (the last listing has 5 entries)"},
+	}
+	for _, tt := range tests {
+		got, err := parseIndexes(tt.args, tt.n)
+		if tt.err != "" {
+			if err == nil || err.Error() != tt.err {
+				t.Errorf("parseIndexes(%q, %d) error = %v, want %q", tt.args, tt.n, err, tt.err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("parseIndexes(%q, %d): %v", tt.args, tt.n, err)
+			continue
+		}
+		if s := fmt.Sprint(got); s != tt.want {
+			t.Errorf("parseIndexes(%q, %d) = %s, want %s", tt.args, tt.n, s, tt.want)
+		}
+	}
+}
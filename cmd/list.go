@@ -1,11 +1,8 @@
 package cmd
 
 import (
-	"encoding/json"
 	"fmt"
 	"net/url"
-	"os"
-	"sort"
 	"strings"
 
 	"github.com/spf13/cobra"
@@ -13,87 +10,117 @@ import (
 	"github.com/amaterasu/markdex-cli/internal/api"
 	"github.com/amaterasu/markdex-cli/internal/cache"
 	"github.com/amaterasu/markdex-cli/internal/config"
+	"github.com/amaterasu/markdex-cli/internal/output"
 )
 
 var (
-	flagTag     string
+	flagTags    tagFlags
 	flagSearch  string
 	flagJSON    bool
 	flagNoCache bool
+	flagHere    bool
+	flagSort    string
+	flagReverse bool
+	flagLimit   int
+	flagGroupBy string
 )
 
 var listCmd = &cobra.Command{
-	Use:   "list [search]",
-	Short: "List bookmarks (optionally filter by search query)",
-	Long:  "List bookmarks. Optionally provide a search query as a positional argument, e.g. 'markdex list rust'. You can also use the -s flag for search, or -t for tag filtering.",
+	Use:   "list [query...]",
+	Short: "List bookmarks (optionally filtered by a query)",
+	Long: `List bookmarks, optionally filtered by a query evaluated locally over the cached collection:
+
+  markdex list tag:go -tag:old site:github.com usage>5 in:inbox.md "exact phrase"
+
+Terms must all match; prefix a term with '-' to negate it. Because negated terms look like
+flags, put the query after -- when using them: markdex list -- tag:go -tag:old
+  word, "a phrase"    title or description contains the text
+  tag:go              has the tag (tag:go,rust for any of several)
+  site:github.com     URL host or one of its subdomains
+  in:inbox.md         source file
+  section:Go          section
+  title:, desc:, url: field contains the value
+  hash:abc            hash starts with the value
+  usage>5             usage compared with >, >=, <, <=, =
+
+Use @name to include a saved search (see markdex saved). Use -s for a server-side search instead. Tag filters: repeat -t to require several tags, --any-tag for alternatives, --exclude-tag to skip tags and --untagged for bookmarks without tags.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		// If a positional argument is provided, treat it as a search query
-		if len(args) > 0 && flagSearch == "" {
-			flagSearch = strings.Join(args, " ")
-		}
 		cfg, _ := config.Load()
+		// Positional arguments form a query evaluated locally (see the query syntax in --help).
+		q, saved, err := parseQuery(cfg, args)
+		if err != nil {
+			return err
+		}
+		if saved != nil && saved.Output != "" && !cmd.Flags().Changed("output") && !cmd.Flags().Changed("format") {
+			if outFormat, err = output.Parse(saved.Output); err != nil {
+				return err
+			}
+		}
 		base := firstNonEmpty(apiBase, cfg.APIBase)
 		if base == "" {
 			return fmt.Errorf("API base not set (use markdex config set --api <url>)")
 		}
 
-		c := cache.New()
-		if !flagNoCache {
-			if items, ok := c.Read(); ok && (flagSearch == "" && flagTag == "") {
-				return output(items, flagJSON)
+		var scope *config.Scope
+		if flagHere {
+			proj, err := requireProject()
+			if err != nil {
+				return err
 			}
+			scope = &proj.Scope
 		}
 
-		query := url.Values{}
-		if flagSearch != "" {
-			query.Set("q", flagSearch)
+		sortBy := flagSort
+		if sortBy == "" && saved != nil {
+			sortBy = saved.Sort
+		}
+		sortBy = firstNonEmpty(sortBy, cfg.DefaultSort)
+		if !validSort(sortBy) {
+			return fmt.Errorf("invalid --sort %q (valid: %s)", sortBy, strings.Join(sortKeys, ", "))
 		}
-		if flagTag != "" {
-			query.Set("tags", flagTag)
+		if flagGroupBy != "" && !validGroup(flagGroupBy) {
+			return fmt.Errorf("invalid --group-by %q (valid: %s)", flagGroupBy, strings.Join(groupKeys, ", "))
+		}
+
+		tags, err := flagTags.filter()
+		if err != nil {
+			return err
 		}
-		items, err := api.FetchBookmarks(base, query)
+		items, err := queryBookmarks(base, cfg, flagSearch, tags, flagNoCache)
 		if err != nil {
 			return err
 		}
-		// simple sort by title for deterministic output
-		sort.Slice(items, func(i, j int) bool { return strings.ToLower(items[i].Title) < strings.ToLower(items[j].Title) })
-		if flagSearch == "" && flagTag == "" {
-			c.Write(items)
+		items = q.Apply(items)
+		if scope != nil {
+			items = filterScope(items, *scope)
+		}
+		sortBookmarks(items, sortBy)
+		if flagReverse {
+			reverseBookmarks(items)
+		}
+		if flagLimit > 0 && len(items) > flagLimit {
+			items = items[:flagLimit]
 		}
-		return output(items, flagJSON)
+		if flagGroupBy != "" {
+			groups := groupBookmarks(items, flagGroupBy)
+			rememberListing(base, cfg, flattenGroups(groups))
+			return printGroups(groups, flagJSON)
+		}
+		rememberListing(base, cfg, items)
+		return printBookmarks(items, flagJSON)
 	},
 }
 
 func init() {
-	listCmd.Flags().StringVarP(&flagTag, "tag", "t", "", "Filter by tag")
-	listCmd.Flags().StringVarP(&flagSearch, "search", "s", "", "Search query")
-	listCmd.Flags().BoolVar(&flagJSON, "json", false, "Output JSON")
+	flagTags.register(listCmd.Flags())
+	listCmd.Flags().StringVarP(&flagSearch, "search", "s", "", "Server-side search query")
+	listCmd.Flags().BoolVar(&flagJSON, "json", false, "Output JSON (same as --output json)")
 	listCmd.Flags().BoolVar(&flagNoCache, "no-cache", false, "Bypass local cache")
-}
-
-func output(items []api.Bookmark, asJSON bool) error {
-	if asJSON {
-		enc := json.NewEncoder(os.Stdout)
-		enc.SetIndent("", "  ")
-		return enc.Encode(items)
-	}
-	if len(items) == 0 {
-		fmt.Println("No matching entries found.")
-		return nil
-	}
-	for _, b := range items {
-		fmt.Printf("%s  %-47s %s\n", b.Hash[:7], truncate(b.Title, 40), b.Tags)
-	}
-
-	return nil
-}
-
-func truncate(s string, n int) string {
-	if len([]rune(s)) <= n {
-		return s
-	}
-	r := []rune(s)
-	return string(r[:n-1]) + "…"
+	listCmd.Flags().BoolVar(&flagHere, "here", false, "Only bookmarks in the scope of the nearest .markdex.toml")
+	listCmd.Flags().StringVar(&flagSort, "sort", "", "Sort by: "+strings.Join(sortKeys, ", ")+" (default from config defaultSort)")
+	listCmd.Flags().BoolVar(&flagReverse, "reverse", false, "Reverse the sort order")
+	listCmd.Flags().IntVar(&flagLimit, "limit", 0, "Show at most N bookmarks (0 = no limit)")
+	listCmd.Flags().StringVar(&flagGroupBy, "group-by", "", "Group results under headings by: "+strings.Join(groupKeys, ", "))
 }
 
 func firstNonEmpty(xs ...string) string {
@@ -104,3 +131,20 @@ func firstNonEmpty(xs ...string) string {
 	}
 	return ""
 }
+
+// loadBookmarks returns the full bookmark collection, from the local cache when fresh
+// (unless noCache is set) or from the API, refreshing the cache.
+func loadBookmarks(base string, cfg *config.Config, noCache bool) ([]api.Bookmark, error) {
+	c := cache.New(cfg.CacheTTL)
+	if !noCache {
+		if items, ok := c.Read(); ok {
+			return items, nil
+		}
+	}
+	items, err := api.FetchBookmarks(base, url.Values{})
+	if err != nil {
+		return nil, err
+	}
+	c.Write(items)
+	return items, nil
+}
@@ -0,0 +1,114 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/amaterasu/markdex-cli/internal/api"
+	"github.com/amaterasu/markdex-cli/internal/config"
+	"github.com/amaterasu/markdex-cli/internal/output"
+)
+
+var (
+	normalizeFlagDryRun bool
+	normalizeFlagYes    bool
+)
+
+// urlChange is a bookmark whose URL normalize rewrites.
+type urlChange struct {
+	Hash  string `json:"hash" yaml:"hash"`
+	Title string `json:"title" yaml:"title"`
+	From  string `json:"from" yaml:"from"`
+	To    string `json:"to" yaml:"to"`
+}
+
+var normalizeCmd = &cobra.Command{
+	Use:   "normalize",
+	Short: "Clean up the URLs of all bookmarks with the urlRules (tracking parameters, host case, ports...)",
+	Long: "Rewrite every bookmark's URL with the configured urlRules (see markdex config list) and list\n" +
+		"bookmarks that turn out to be the same page. --dry-run only shows what would change.",
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, _ := config.Load()
+		base := firstNonEmpty(apiBase, cfg.APIBase)
+		if base == "" {
+			return fmt.Errorf("API base not set (use markdex config set --api <url>)")
+		}
+		items, err := loadBookmarks(base, cfg, true)
+		if err != nil {
+			return err
+		}
+		norm := cfg.URLNormalizer()
+		var changes []urlChange
+		for _, b := range items {
+			if to := norm.Normalize(b.URL); to != b.URL {
+				changes = append(changes, urlChange{Hash: b.Hash, Title: b.Title, From: b.URL, To: to})
+			}
+		}
+		reportDuplicates(cfg, items)
+		if len(changes) == 0 {
+			infof("All URLs are already normalized")
+			return nil
+		}
+		t := output.Tabular{Header: []string{"HASH", "TITLE", "FROM", "TO"}}
+		for _, c := range changes {
+			t.Rows = append(t.Rows, []string{c.Hash, c.Title, c.From, c.To})
+		}
+		if err := printRows(changes, t); err != nil {
+			return err
+		}
+		if normalizeFlagDryRun {
+			return nil
+		}
+		if !normalizeFlagYes && !confirm(fmt.Sprintf("Update %d bookmark(s)?", len(changes))) {
+			infof("Nothing changed")
+			return nil
+		}
+		failed := 0
+		for _, c := range changes {
+			to := c.To
+			if _, err := api.UpdateBookmark(base, c.Hash, api.UpdateBookmarkRequest{URL: &to}); err != nil {
+				fmt.Fprintf(os.Stderr, "%s: %v\n", c.Hash, err)
+				failed++
+			}
+		}
+		invalidateCache(cfg)
+		fmt.Printf("Updated %d bookmark(s)\n", len(changes)-failed)
+		if failed > 0 {
+			return fmt.Errorf("%d update(s) failed", failed)
+		}
+		return nil
+	},
+}
+
+// reportDuplicates notes on stderr the bookmarks that are the same page after normalizing.
+func reportDuplicates(cfg *config.Config, items []api.Bookmark) {
+	norm := cfg.URLNormalizer()
+	groups := map[string][]api.Bookmark{}
+	var keys []string
+	for _, b := range items {
+		k := norm.Key(b.URL)
+		if groups[k] == nil {
+			keys = append(keys, k)
+		}
+		groups[k] = append(groups[k], b)
+	}
+	for _, k := range keys {
+		if dups := groups[k]; len(dups) > 1 {
+			hashes := make([]string, len(dups))
+			for i, b := range dups {
+				hashes[i] = b.Hash
+			}
+			fmt.Fprintf(os.Stderr, "Same page: %s (%s); remove extras with markdex rm\n", strings.Join(hashes, ", "), dups[0].URL)
+		}
+	}
+}
+
+func init() {
+	normalizeCmd.Flags().BoolVar(&normalizeFlagDryRun, "dry-run", false, "Only show the changes")
+	normalizeCmd.Flags().BoolVarP(&normalizeFlagYes, "yes", "y", false, "Update without asking")
+	rootCmd.AddCommand(normalizeCmd)
+}
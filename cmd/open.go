@@ -4,78 +4,401 @@ import (
 	"errors"
 	"fmt"
 	"net/url"
+	"os"
+	"os/exec"
 	"sort"
 	"strings"
+	"time"
 
+	"github.com/skip2/go-qrcode"
 	"github.com/spf13/cobra"
 
 	"github.com/amaterasu/markdex-cli/internal/api"
+	"github.com/amaterasu/markdex-cli/internal/clipboard"
 	"github.com/amaterasu/markdex-cli/internal/config"
+	"github.com/amaterasu/markdex-cli/internal/history"
+	"github.com/amaterasu/markdex-cli/internal/output"
+	"github.com/amaterasu/markdex-cli/internal/queue"
 	"github.com/amaterasu/markdex-cli/internal/util"
 )
 
+var (
+	openFlagPrint    bool
+	openFlagCopy     bool
+	openFlagMarkdown bool
+	openFlagQR       bool
+	openFlagDelay    time.Duration
+	openFlagLast     bool
+	flagBrowser      string
+)
+
 var openHashCmd = &cobra.Command{
-	Use:   "open <hash-prefix>",
-	Short: "Open a bookmark by its hash prefix (first 3+ chars)",
-	Args:  cobra.ExactArgs(1),
-	RunE: func(cmd *cobra.Command, args []string) error {
+	Use:   "open <index... | hash-prefix... | query...> | --last",
+	Short: "Open bookmarks by position in the last listing, by hash prefix (first 3+ chars, as shown by list) or by a query",
+	Long: `Open bookmarks by their position in the last list, search or pick (e.g. 'markdex open 3' or
+'markdex open 1-3,5'), by one or more hash prefixes, or by a query such as
+'markdex open site:github.com tag:markdex' that must match exactly one bookmark.
+See 'markdex list --help' for the query syntax.
+
+Numbers always refer to positions; use hash:123 for a hash prefix made of digits only.
+Words of hex digits only (such as "cafe") are hash prefixes; use title:cafe to search for one.
+Positions stay valid for an hour and only for the API and user the listing was made with.
+
+Bookmarks open with --browser, else the command of the first matching [[openRules]] entry in
+config, else the browser config key, $BROWSER or the platform default.
 
-		prefix := strings.ToLower(args[0])
-		if len(prefix) < 3 {
-			return errors.New("hash prefix must be at least 3 characters")
+Instead of opening a browser, --print writes the URLs, --markdown writes [title](url) links,
+--copy puts the URLs on the clipboard and --qr draws a QR code per URL in the terminal.
+When opening several tabs, --delay spaces them out so the browser is not flooded.`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if openFlagLast {
+			return cobra.NoArgs(cmd, args)
+		}
+		return cobra.MinimumNArgs(1)(cmd, args)
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if !openFlagLast && strings.TrimSpace(strings.Join(args, "")) == "" {
+			return errors.New("hash prefix or query required")
 		}
 		cfg, _ := config.Load()
 		base := firstNonEmpty(apiBase, cfg.APIBase)
 		if base == "" {
 			return fmt.Errorf("API base not set")
 		}
-		items, err := api.FetchBookmarks(base, url.Values{})
+		var matches []api.Bookmark
+		var err error
+		if openFlagLast {
+			matches, err = lastOpened(base)
+		} else {
+			matches, err = resolveBookmarks(base, cfg, args)
+		}
 		if err != nil {
 			return err
 		}
-		if len(items) == 0 {
-			return errors.New("no bookmarks")
+
+		return openAction(base, cfg, matches)
+	},
+}
+
+func init() {
+	openHashCmd.Flags().BoolVar(&openFlagPrint, "print", false, "Print the URLs instead of opening them")
+	openHashCmd.Flags().BoolVar(&openFlagCopy, "copy", false, "Copy the URLs to the clipboard instead of opening them")
+	openHashCmd.Flags().BoolVar(&openFlagMarkdown, "markdown", false, "Print [title](url) links instead of opening them")
+	openHashCmd.Flags().BoolVar(&openFlagQR, "qr", false, "Show a QR code for each URL instead of opening it")
+	openHashCmd.Flags().DurationVar(&openFlagDelay, "delay", 300*time.Millisecond, "Pause between browser tabs when opening several bookmarks")
+	openHashCmd.Flags().BoolVar(&openFlagLast, "last", false, "Re-open the most recently opened bookmark (see markdex history)")
+	openHashCmd.Flags().StringVar(&flagBrowser, "browser", "", "Command to open URLs with, overriding config and open rules ({url} is replaced by the URL)")
+	openHashCmd.MarkFlagsMutuallyExclusive("print", "copy", "markdown", "qr")
+	rootCmd.AddCommand(openHashCmd)
+}
+
+// recordUse reports that b was used to the server and adds it to the local history;
+// command names what used it (e.g. "open --copy").
+func recordUse(base string, cfg *config.Config, b api.Bookmark, command string) {
+	if strings.TrimSpace(b.Hash) == "" {
+		return
+	}
+	values := url.Values{}
+	values.Set("hash", b.Hash)
+	values.Set("user_id", cfg.UserID)
+	if _, err := api.UseBookmark(base, values); api.Retryable(err) {
+		// keep the event for markdex sync or the next successful request
+		if queue.Add(queue.Event{Base: base, Hash: b.Hash, UserID: cfg.UserID}) == nil && !queuedNoted {
+			queuedNoted = true
+			infof("Server unreachable; usage queued for markdex sync")
 		}
-		// Stable order by title for deterministic ambiguity listing
-		sort.Slice(items, func(i, j int) bool { return strings.ToLower(items[i].Title) < strings.ToLower(items[j].Title) })
-		var matches []api.Bookmark
+	}
+	history.Record(history.Entry{Hash: b.Hash, Title: b.Title, URL: b.URL, Command: command})
+}
+
+// lastOpened returns the most recent bookmark in the history, with current data from the
+// server when it still exists there.
+func lastOpened(base string) ([]api.Bookmark, error) {
+	entries, err := history.Load()
+	if err != nil {
+		return nil, err
+	}
+	if len(entries) == 0 {
+		return nil, errors.New("no bookmarks opened yet")
+	}
+	last := entries[len(entries)-1]
+	if items, err := api.FetchBookmarks(base, url.Values{}); err == nil {
 		for _, b := range items {
-			if b.Hash == "" {
-				continue
+			if b.Hash == last.Hash {
+				return []api.Bookmark{b}, nil
 			}
-			if strings.HasPrefix(strings.ToLower(b.Hash), prefix) {
-				matches = append(matches, b)
+		}
+	}
+	return []api.Bookmark{{Hash: last.Hash, Title: last.Title, URL: last.URL}}, nil
+}
+
+// resolveBookmarks finds the bookmarks args refer to: positions in the last listing, hash
+// prefixes (looked up in a fresh copy from the server) or a query matching one bookmark.
+func resolveBookmarks(base string, cfg *config.Config, args []string) ([]api.Bookmark, error) {
+	var indexErr error
+	if isIndexSpec(args) {
+		matches, err := fromListing(base, cfg, args)
+		if err == nil || !allNumbers(args) {
+			return matches, err
+		}
+		// not a valid position, but it may be a hash prefix made of digits
+		indexErr = err
+	}
+	items, err := api.FetchBookmarks(base, url.Values{})
+	if err != nil {
+		return nil, err
+	}
+	if indexErr != nil {
+		for _, a := range args {
+			if !hasHashPrefix(items, a) {
+				return nil, indexErr
 			}
 		}
-		if len(matches) == 0 {
-			return fmt.Errorf("no bookmark with hash prefix %s", prefix)
-		}
-		if len(matches) > 1 {
-			// list ambiguous options (limit to 10 for brevity)
-			var lines []string
-			for i, m := range matches {
-				if i >= 10 { // cap
-					lines = append(lines, fmt.Sprintf("... and %d more", len(matches)-10))
-					break
-				}
-				shortHash := m.Hash
-				if len(shortHash) > 7 {
-					shortHash = shortHash[:7]
-				}
-				lines = append(lines, fmt.Sprintf("%s  %s", shortHash, m.Title))
+	}
+	if len(items) == 0 {
+		return nil, errors.New("no bookmarks")
+	}
+	return findBookmarks(cfg, items, args)
+}
+
+// allNumbers reports whether every arg is a plain number (no ranges or lists).
+func allNumbers(args []string) bool {
+	for _, a := range args {
+		if strings.Trim(a, "0123456789") != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// findBookmarks resolves args as one or more hash prefixes when they all look like hashes,
+// or else as a query that must match exactly one bookmark. A mistyped prefix is reported
+// rather than searched for as text.
+func findBookmarks(cfg *config.Config, items []api.Bookmark, args []string) ([]api.Bookmark, error) {
+	for _, a := range args {
+		if !looksLikeHash(a) {
+			match, err := findByQuery(cfg, items, args)
+			if err != nil {
+				return nil, err
 			}
-			return fmt.Errorf("ambiguous hash prefix %s, matches:\n%s", prefix, strings.Join(lines, "\n"))
+			return []api.Bookmark{match}, nil
 		}
+	}
+	var out []api.Bookmark
+	seen := map[string]bool{}
+	for _, a := range args {
+		match, err := findByPrefix(items, a)
+		if err != nil && !hasHashPrefix(items, a) && len(args) == 1 {
+			return nil, fmt.Errorf("%w (to search for %q as text, use title:%s)", err, a, a)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if !seen[match.Hash] {
+			seen[match.Hash] = true
+			out = append(out, match)
+		}
+	}
+	return out, nil
+}
 
-		values := url.Values{}
-		values.Set("hash", matches[0].Hash)
-		values.Set("user_id", cfg.UserID)
-		api.UseBookmark(base, values)
+// looksLikeHash reports whether s could be (part of) a hash: hex digits only.
+func looksLikeHash(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, c := range strings.ToLower(s) {
+		if !('0' <= c && c <= '9' || 'a' <= c && c <= 'f') {
+			return false
+		}
+	}
+	return true
+}
 
-		return util.OpenBrowser(matches[0].URL)
-	},
+// openAction does what the action flags ask with the resolved bookmarks; by default it
+// opens each in the browser. Opened and copied bookmarks are recorded as used (see
+// recordUse); those only printed or shown as QR codes are not.
+func openAction(base string, cfg *config.Config, items []api.Bookmark) error {
+	switch {
+	case openFlagPrint:
+		for _, b := range items {
+			fmt.Println(b.URL)
+		}
+	case openFlagMarkdown:
+		for _, b := range items {
+			fmt.Println(markdownLink(b))
+		}
+	case openFlagCopy:
+		urls := make([]string, len(items))
+		for i, b := range items {
+			urls[i] = b.URL
+		}
+		if err := clipboard.Write(cfg.Clipboard, strings.Join(urls, "\n")); err != nil {
+			return err
+		}
+		for _, b := range items {
+			recordUse(base, cfg, b, "open --copy")
+		}
+	case openFlagQR:
+		for i, b := range items {
+			qr, err := qrcode.New(b.URL, qrcode.Medium)
+			if err != nil {
+				return fmt.Errorf("%s: %w", b.URL, err)
+			}
+			if i > 0 {
+				fmt.Println()
+			}
+			fmt.Println(b.Title)
+			fmt.Println(b.URL)
+			fmt.Print(qr.ToSmallString(false))
+		}
+	default:
+		for i, b := range items {
+			if i > 0 && openFlagDelay > 0 {
+				time.Sleep(openFlagDelay)
+			}
+			used, err := openURL(cfg, b)
+			if err != nil {
+				return err
+			}
+			if used {
+				recordUse(base, cfg, b, "open")
+			}
+		}
+	}
+	return nil
 }
 
-func init() {
-	rootCmd.AddCommand(openHashCmd)
+// openURL opens b in the browser chosen by --browser, the first matching open rule,
+// the browser config key, $BROWSER or the platform default, in that order. Without a
+// display for the platform default (e.g. over SSH) it falls back to remoteOpen. used is
+// false when the URL was only printed.
+func openURL(cfg *config.Config, b api.Bookmark) (used bool, err error) {
+	browser := firstNonEmpty(flagBrowser, cfg.BrowserFor(b.Tags, b.URL), util.BrowserFromEnv())
+	if browser == "" && util.Headless() {
+		return openRemote(cfg, b.URL)
+	}
+	return true, util.OpenBrowser(browser, b.URL)
+}
+
+// queuedNoted is set once recordUse has said that usage is being queued.
+var queuedNoted bool
+
+// remoteNoted is set once openRemote has explained why it prints URLs.
+var remoteNoted bool
+
+// openRemote hands url to the user when no local browser can show it; used reports
+// whether it was copied or handed to remoteCommand rather than only printed.
+func openRemote(cfg *config.Config, url string) (used bool, err error) {
+	switch cfg.RemoteOpen {
+	case "osc52":
+		if err := clipboard.Write("osc52", url); err != nil {
+			return false, err
+		}
+		fmt.Fprintf(os.Stderr, "No display; copied %s to your local clipboard\n", url)
+		return true, nil
+	case "command":
+		fields := strings.Fields(cfg.RemoteCommand)
+		if len(fields) == 0 {
+			return false, errors.New("remoteOpen is command but remoteCommand is not set (markdex config set remoteCommand '<command> {url}')")
+		}
+		c := exec.Command(fields[0], util.WithURL(fields[0], fields[1:], url)...)
+		c.Stdout, c.Stderr = os.Stderr, os.Stderr
+		if err := c.Run(); err != nil {
+			return false, fmt.Errorf("remoteCommand: %w", err)
+		}
+		return true, nil
+	}
+	if !remoteNoted {
+		remoteNoted = true
+		fmt.Fprintln(os.Stderr, "No display to open a browser on; open these URLs yourself:")
+	}
+	fmt.Println(url)
+	return false, nil
+}
+
+// markdownLink formats b as a Markdown link, escaping brackets in the title.
+func markdownLink(b api.Bookmark) string {
+	title := strings.NewReplacer("[", `\[`, "]", `\]`).Replace(firstNonEmpty(b.Title, b.URL))
+	return "[" + title + "](" + b.URL + ")"
+}
+
+// findByPrefix returns the single bookmark whose hash starts with prefix, or an error
+// listing the candidates when the prefix is ambiguous.
+// Prefixes shorter than output.MinHashPrefix only match a hash exactly.
+func findByPrefix(items []api.Bookmark, prefix string) (api.Bookmark, error) {
+	prefix = strings.ToLower(prefix)
+	// Stable order by title for deterministic ambiguity listing
+	sort.Slice(items, func(i, j int) bool { return strings.ToLower(items[i].Title) < strings.ToLower(items[j].Title) })
+	var matches []api.Bookmark
+	for _, b := range items {
+		if b.Hash == "" {
+			continue
+		}
+		h := strings.ToLower(b.Hash)
+		if h == prefix {
+			return b, nil
+		}
+		if len(prefix) >= output.MinHashPrefix && strings.HasPrefix(h, prefix) {
+			matches = append(matches, b)
+		}
+	}
+	if len(matches) == 0 && len(prefix) < output.MinHashPrefix {
+		return api.Bookmark{}, fmt.Errorf("hash prefix must be at least %d characters", output.MinHashPrefix)
+	}
+	if len(matches) == 0 {
+		return api.Bookmark{}, fmt.Errorf("no bookmark with hash prefix %s", prefix)
+	}
+	if len(matches) > 1 {
+		return api.Bookmark{}, ambiguous("hash prefix "+prefix, matches)
+	}
+	return matches[0], nil
+}
+
+// hasHashPrefix reports whether s is the hash (or a hash prefix of usable length) of any bookmark.
+func hasHashPrefix(items []api.Bookmark, s string) bool {
+	s = strings.ToLower(s)
+	for _, b := range items {
+		h := strings.ToLower(b.Hash)
+		if h != "" && (h == s || len(s) >= output.MinHashPrefix && strings.HasPrefix(h, s)) {
+			return true
+		}
+	}
+	return false
+}
+
+// findByQuery returns the single bookmark matching the query in args.
+func findByQuery(cfg *config.Config, items []api.Bookmark, args []string) (api.Bookmark, error) {
+	q, _, err := parseQuery(cfg, args)
+	if err != nil {
+		return api.Bookmark{}, err
+	}
+	matches := q.Apply(items)
+	sort.Slice(matches, func(i, j int) bool { return strings.ToLower(matches[i].Title) < strings.ToLower(matches[j].Title) })
+	switch len(matches) {
+	case 0:
+		return api.Bookmark{}, fmt.Errorf("no bookmark matches %s", joinQuery(args))
+	case 1:
+		return matches[0], nil
+	}
+	return api.Bookmark{}, ambiguous("query "+joinQuery(args), matches)
+}
+
+// ambiguous builds the error listing the candidates (at most 10) for an ambiguous lookup,
+// with hash prefixes that tell them apart.
+func ambiguous(what string, matches []api.Bookmark) error {
+	hashes := make([]string, len(matches))
+	for i, m := range matches {
+		hashes[i] = m.Hash
+	}
+	output.IndexHashes(hashes, output.MinHashPrefix)
+	var lines []string
+	for i, m := range matches {
+		if i >= 10 { // cap
+			lines = append(lines, fmt.Sprintf("... and %d more", len(matches)-10))
+			break
+		}
+		lines = append(lines, fmt.Sprintf("%s  %s", output.ShortHash(m.Hash), m.Title))
+	}
+	return fmt.Errorf("ambiguous %s, matches:\n%s", what, strings.Join(lines, "\n"))
 }
@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/amaterasu/markdex-cli/internal/api"
+	"github.com/amaterasu/markdex-cli/internal/config"
+)
+
+func TestFindBookmarks(t *testing.T) {
+	items := []api.Bookmark{
+		{Hash: "abc123", Title: "Go blog", URL: "https://go.dev/blog"},
+		{Hash: "abd456", Title: "Dead links checker", URL: "https://example.com/dead"},
+		{Hash: "f00d00", Title: "Cafe menu", URL: "https://example.com/cafe", Tags: []string{"food"}},
+	}
+	tests := []struct {
+		args []string
+		want string // hashes, or the error
+	}{
+		{[]string{"abc"}, "abc123"},
+		{[]string{"ABC1"}, "abc123"},
+		{[]string{"abc", "f00", "abc123"}, "abc123,f00d00"},
+		{[]string{"blog"}, "abc123"},
+		{[]string{"tag:food"}, "f00d00"},
+		{[]string{"title:cafe"}, "f00d00"},
+		{[]string{"menu", "cafe"}, "f00d00"},
+		// hex words are prefixes, even when the text would match something
+		{[]string{"dead"}, `no bookmark with hash prefix dead (to search for "dead" as text, use title:dead)`},
+		{[]string{"cafe"}, `no bookmark with hash prefix cafe (to search for "cafe" as text, use title:cafe)`},
+		{[]string{"ab"}, `hash prefix must be at least 3 characters (to search for "ab" as text, use title:ab)`},
+		{[]string{"abc", "dead"}, "no bookmark with hash prefix dead"},
+		{[]string{"abd"}, "abd456"},
+		{[]string{"ab1"}, "no bookmark with hash prefix ab1 (to search for \"ab1\" as text, use title:ab1)"},
+		{[]string{"nothing"}, "no bookmark matches nothing"},
+		{[]string{"abc", "blog"}, "no bookmark matches abc blog"}, // non-hex text makes it a query
+	}
+	for _, tt := range tests {
+		got, err := findBookmarks(&config.Config{}, append([]api.Bookmark(nil), items...), tt.args)
+		var s string
+		if err != nil {
+			s = err.Error()
+		} else {
+			var hs []string
+			for _, b := range got {
+				hs = append(hs, b.Hash)
+			}
+			s = strings.Join(hs, ",")
+		}
+		if s != tt.want {
+			t.Errorf("findBookmarks(%q) = %q, want %q", tt.args, s, tt.want)
+		}
+	}
+}
+
+func TestOpenRemote(t *testing.T) {
+	stdout := os.Stdout
+	os.Stdout, _ = os.Open(os.DevNull)
+	defer func() { os.Stdout = stdout }()
+	tests := []struct {
+		remoteOpen, command string
+		used                bool
+		err                 bool
+	}{
+		{remoteOpen: "print"},
+		{remoteOpen: "command", command: "true {url}", used: true},
+		{remoteOpen: "command", command: "false", err: true},
+		{remoteOpen: "command", err: true},
+	}
+	for _, tt := range tests {
+		cfg := &config.Config{RemoteOpen: tt.remoteOpen, RemoteCommand: tt.command}
+		used, err := openRemote(cfg, "https://example.com/")
+		if used != tt.used || (err != nil) != tt.err {
+			t.Errorf("openRemote with %s %q = %v, %v; want used %v", tt.remoteOpen, tt.command, used, err, tt.used)
+		}
+	}
+}
@@ -4,227 +4,439 @@ import (
 	"bufio"
 	"errors"
 	"fmt"
-	"net/url"
 	"os"
 	"os/exec"
-	"runtime"
-	"sort"
+	"slices"
 	"strings"
 
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 
 	"github.com/amaterasu/markdex-cli/internal/api"
 	"github.com/amaterasu/markdex-cli/internal/cache"
+	"github.com/amaterasu/markdex-cli/internal/clipboard"
 	"github.com/amaterasu/markdex-cli/internal/config"
+	"github.com/amaterasu/markdex-cli/internal/output"
+	"github.com/amaterasu/markdex-cli/internal/picker"
 	"github.com/amaterasu/markdex-cli/internal/util"
 )
 
 var (
-	pickFlagTag     string
+	pickFlagTags    tagFlags
 	pickFlagSearch  string
 	pickFlagMulti   bool
 	pickFlagCopy    bool
 	pickFlagNoCache bool
 	pickFlagFzfPath string
+	pickFlagHere    bool
+	pickFlagQuery   string
+	pickFlagPicker  string
+	pickFlagSort    string
+	pickFlagEmit    bool
+	pickFlagNarrow  int
+	pickFlagAI      string
 )
 
 var pickCmd = &cobra.Command{
-	Use:   "pick [initial-query]",
-	Short: "Fuzzy-pick bookmarks via fzf",
-	Long:  "Loads bookmarks (optionally filtered) and invokes external 'fzf' for fuzzy selection. Enter opens, --multi allows multiple selection. Ctrl-Y copies the hash of the highlighted entry. An optional initial-query argument seeds the fuzzy filter (client-side only).",
-	Args:  cobra.MaximumNArgs(1),
+	Use:   "pick [query...]",
+	Short: "Fuzzy-pick bookmarks via fzf or the built-in picker",
+	Long:  "Loads bookmarks (optionally filtered) and invokes external 'fzf' for fuzzy selection, or a built-in picker when fzf is not installed (or with --picker builtin). Enter opens, --multi allows multiple selection (Tab marks entries in the built-in picker). Ctrl-Y copies the hash of the highlighted entry in fzf. Positional arguments are a query (see 'markdex list --help') that narrows the candidates; --query seeds the fuzzy filter instead.",
 	RunE: func(cmd *cobra.Command, args []string) error {
 		cfg, _ := config.Load()
+		q, saved, err := parseQuery(cfg, args)
+		if err != nil {
+			return err
+		}
 		base := firstNonEmpty(apiBase, cfg.APIBase)
 		if base == "" {
 			return fmt.Errorf("API base not set")
 		}
-		fzfPath := pickFlagFzfPath
-		if fzfPath == "" {
-			fzfPath = "fzf"
-		}
-		if _, err := exec.LookPath(fzfPath); err != nil {
-			return errors.New("fzf not found in PATH (install: https://github.com/junegunn/fzf)")
+		usefzf, fzfPath, err := choosePicker()
+		if err != nil {
+			return err
 		}
 
-		c := cache.New()
-		var items []api.Bookmark
-		var ok bool
-		useCache := pickFlagSearch == "" && pickFlagTag == "" && !pickFlagNoCache
-		if useCache {
-			items, ok = c.Read()
-		}
-		if !ok {
-			q := url.Values{}
-			if pickFlagSearch != "" {
-				q.Set("q", pickFlagSearch)
-			}
-			if pickFlagTag != "" {
-				q.Set("tags", pickFlagTag)
-			}
-			fetched, err := api.FetchBookmarks(base, q)
+		var scope *config.Scope
+		if pickFlagHere {
+			proj, err := requireProject()
 			if err != nil {
 				return err
 			}
-			items = fetched
-			if useCache {
-				c.Write(items)
-			}
+			scope = &proj.Scope
 		}
-		if len(items) == 0 {
-			return errors.New("no bookmarks")
-		}
-
-		// stable sort by title
-		sort.Slice(items, func(i, j int) bool { return strings.ToLower(items[i].Title) < strings.ToLower(items[j].Title) })
 
-		lines := make([]string, len(items))
-		for i, b := range items {
-			shortHash := b.Hash
-			if len(shortHash) > 7 {
-				shortHash = shortHash[:7]
-			}
-			tags := strings.Join(b.Tags, ",")
-			// Don't hard-truncate description; rely on preview wrapping for readability.
-			desc := b.Description
-			// Columns: index, shortHash, title, tags, description
-			lines[i] = fmt.Sprintf("%d\t%s\t%-40s\t%s\t%s", i, shortHash, sanitizeTabs(truncate(b.Title, 40)), tags, sanitizeTabs(desc))
-		}
-
-		// Show only short hash and title (cols 2,3)
-		fzfArgs := []string{"--with-nth", "2,3", "--delimiter", "\t", "--ansi", "--prompt", "markdex> "}
-		if len(args) == 1 && args[0] != "" {
-			fzfArgs = append(fzfArgs, "--query", args[0])
-		}
-		if pickFlagMulti {
-			fzfArgs = append(fzfArgs, "--multi")
-		}
-		// Preview includes tags and wraps description to available preview width (if 'fold' exists)
-		preview := "echo TITLE: {3}; echo TAGS: {4}; echo HASH: {2}; echo; echo DESCRIPTION:; (command -v fold >/dev/null 2>&1 && echo {5} | fold -s -w ${FZF_PREVIEW_COLUMNS:-100}) || echo {5}"
-		fzfArgs = append(fzfArgs, "--preview", preview)
-		// Key binding: Ctrl-Y copies hash (column 2) to clipboard (macOS pbcopy). Abort to exit without opening.
-		fzfArgs = append(fzfArgs, "--bind", "ctrl-y:execute-silent(echo -n {2} | pbcopy)+abort")
-		cmdFzf := exec.Command(fzfPath, fzfArgs...)
-		stdin, err := cmdFzf.StdinPipe()
+		tags, err := pickFlagTags.filter()
 		if err != nil {
 			return err
 		}
-		cmdFzf.Stderr = os.Stderr
-		out, err := cmdFzf.StdoutPipe()
+		if pickFlagNarrow > 0 {
+			// fzf's tag action: keep only bookmarks sharing the first tag of that entry.
+			if l, ok := cache.ReadListing(); ok && pickFlagNarrow <= len(l.Items) && len(l.Items[pickFlagNarrow-1].Tags) > 0 {
+				tags.All = append(tags.All, l.Items[pickFlagNarrow-1].Tags[0])
+			}
+		}
+		aiMode := cmd.Flags().Changed("ai-search")
+		var items []api.Bookmark
+		switch {
+		case aiMode && strings.TrimSpace(pickFlagAI) == "":
+			// AI search without a query yet: no candidates.
+		case aiMode:
+			items, err = api.SearchAI(base, pickFlagAI)
+			items = tags.Apply(items)
+		default:
+			items, err = queryBookmarks(base, cfg, pickFlagSearch, tags, pickFlagNoCache)
+		}
 		if err != nil {
 			return err
 		}
-
-		if err := cmdFzf.Start(); err != nil {
-			return err
+		items = q.Apply(items)
+		if scope != nil {
+			items = filterScope(items, *scope)
 		}
-		go func() {
-			w := bufio.NewWriter(stdin)
-			for _, l := range lines {
-				fmt.Fprintln(w, l)
-			}
-			w.Flush()
-			stdin.Close()
-		}()
-
-		scanner := bufio.NewScanner(out)
-		var selected []int
-		for scanner.Scan() {
-			parts := strings.Split(scanner.Text(), "\t")
-			if len(parts) == 0 {
-				continue
+		if len(items) == 0 && !pickFlagEmit {
+			return errors.New("no bookmarks")
+		}
+
+		if !aiMode { // AI results keep their relevance order
+			sortBy := pickFlagSort
+			if !cmd.Flags().Changed("sort") && saved != nil && saved.Sort != "" {
+				sortBy = saved.Sort
 			}
-			idxStr := parts[0]
-			var idx int
-			fmt.Sscanf(idxStr, "%d", &idx)
-			if idx >= 0 && idx < len(items) {
-				selected = append(selected, idx)
+			if !validSort(sortBy) {
+				return fmt.Errorf("invalid --sort %q (valid: %s)", sortBy, strings.Join(sortKeys, ", "))
 			}
+			sortBookmarks(items, sortBy)
 		}
-		err = cmdFzf.Wait()
-		// If nothing selected, treat as normal cancel regardless of exit code.
-		if len(selected) == 0 {
+		rememberListing(base, cfg, items)
+
+		indexHashes(items)
+		if pickFlagEmit {
+			// Candidates for an fzf reload binding.
+			for _, l := range fzfLines(items) {
+				fmt.Println(l)
+			}
 			return nil
 		}
-		// If there was an error, ignore common fzf cancel/no-match codes (1, 130), otherwise return it.
-		if err != nil {
-			if ee, ok := err.(*exec.ExitError); ok {
-				code := ee.ExitCode()
-				if code == 1 || code == 130 { // 1=no match, 130=interrupted/ESC
-					return nil
-				}
-			}
+		var selected []api.Bookmark
+		if usefzf {
+			selected, err = pickFzf(cfg, base, fzfPath, items, cmd, args)
+		} else {
+			selected, err = pickBuiltin(items)
+		}
+		if err != nil || len(selected) == 0 {
 			return err
 		}
 
 		if pickFlagCopy {
-			// Track usage for the first selected entry, then copy its URL
-			if h := strings.TrimSpace(items[selected[0]].Hash); h != "" {
-				vals := url.Values{}
-				vals.Set("hash", h)
-				vals.Set("user_id", cfg.UserID)
-				_, _ = api.UseBookmark(base, vals)
+			// Copy the URL of the first selected entry, then track its usage
+			if err := clipboard.Write(cfg.Clipboard, selected[0].URL); err != nil {
+				return err
 			}
-			return copyToClipboard(items[selected[0]].URL)
+			recordUse(base, cfg, selected[0], "pick --copy")
+			return nil
 		}
 
-		// track usage and open each
-		for _, si := range selected {
-			if h := strings.TrimSpace(items[si].Hash); h != "" {
-				vals := url.Values{}
-				vals.Set("hash", h)
-				vals.Set("user_id", cfg.UserID)
-				_, _ = api.UseBookmark(base, vals)
+		// open each and track its usage
+		for _, b := range selected {
+			if used, err := openURL(cfg, b); err == nil && used {
+				recordUse(base, cfg, b, "pick")
 			}
-			_ = util.OpenBrowser(items[si].URL)
 		}
 		return nil
 	},
 }
 
 func init() {
-	pickCmd.Flags().StringVarP(&pickFlagTag, "tag", "t", "", "Filter by tag")
+	pickFlagTags.register(pickCmd.Flags())
 	pickCmd.Flags().StringVarP(&pickFlagSearch, "search", "s", "", "Server-side search query before fuzzy picking")
+	pickCmd.Flags().StringVarP(&pickFlagQuery, "query", "q", "", "Initial fuzzy query")
 	pickCmd.Flags().BoolVar(&pickFlagMulti, "multi", false, "Allow selecting multiple bookmarks")
 	pickCmd.Flags().BoolVar(&pickFlagCopy, "copy", false, "Copy first selected URL to clipboard instead of opening")
 	pickCmd.Flags().BoolVar(&pickFlagNoCache, "no-cache", false, "Bypass local cache")
+	pickCmd.Flags().BoolVar(&pickFlagHere, "here", false, "Only bookmarks in the scope of the nearest .markdex.toml")
+	pickCmd.Flags().StringVar(&flagBrowser, "browser", "", "Command to open URLs with, overriding config and open rules ({url} is replaced by the URL)")
 	pickCmd.Flags().StringVar(&pickFlagFzfPath, "fzf", "", "Path to fzf binary (defaults to looking in PATH)")
+	pickCmd.Flags().StringVar(&pickFlagSort, "sort", "frecency", "Order of the candidates: "+strings.Join(sortKeys, ", "))
+	pickCmd.Flags().StringVar(&pickFlagPicker, "picker", "auto", "Picker to use: auto (fzf when installed), fzf or builtin")
+	pickCmd.Flags().BoolVar(&pickFlagEmit, "emit-lines", false, "Print fzf candidate lines and exit (used by key bindings)")
+	pickCmd.Flags().IntVar(&pickFlagNarrow, "narrow-tag-of", 0, "Also require the first tag of this entry of the last listing (used by key bindings)")
+	pickCmd.Flags().StringVar(&pickFlagAI, "ai-search", "", "Use AI search results for this query as candidates (used by key bindings)")
+	for _, f := range []string{"emit-lines", "narrow-tag-of", "ai-search"} {
+		_ = pickCmd.Flags().MarkHidden(f)
+	}
+	rootCmd.AddCommand(copyCmd)
+	rootCmd.AddCommand(pickModeCmd)
 }
 
-func sanitizeTabs(s string) string { return strings.ReplaceAll(s, "\t", " ") }
+// choosePicker resolves --picker: whether to use fzf (and its path) or the built-in picker.
+func choosePicker() (usefzf bool, fzfPath string, err error) {
+	fzfPath = firstNonEmpty(pickFlagFzfPath, "fzf")
+	_, lookErr := exec.LookPath(fzfPath)
+	switch pickFlagPicker {
+	case "auto", "":
+		return lookErr == nil, fzfPath, nil
+	case "fzf":
+		if lookErr != nil {
+			return false, "", errors.New("fzf not found in PATH (install: https://github.com/junegunn/fzf, or use --picker builtin)")
+		}
+		return true, fzfPath, nil
+	case "builtin":
+		return false, "", nil
+	}
+	return false, "", fmt.Errorf("invalid --picker %q (valid: auto, fzf, builtin)", pickFlagPicker)
+}
+
+// pickBuiltin lets the user choose among items with the in-process picker.
+func pickBuiltin(items []api.Bookmark) ([]api.Bookmark, error) {
+	candidates := make([]picker.Item, len(items))
+	for i, b := range items {
+		tags := strings.Join(b.Tags, ",")
+		label := output.ShortHash(b.Hash) + "  " + flattenLine(b.Title)
+		if tags != "" {
+			label += "  [" + tags + "]"
+		}
+		candidates[i] = picker.Item{Label: label, Fields: []string{b.Title, tags, b.Hash, b.URL}}
+	}
+	chosen, err := picker.Run(candidates, picker.Options{
+		Prompt:  "markdex> ",
+		Query:   pickFlagQuery,
+		Multi:   pickFlagMulti,
+		Preview: func(i, width int) []string { return output.Preview(items[i], width, output.Term{}) },
+	})
+	selected := make([]api.Bookmark, len(chosen))
+	for i, c := range chosen {
+		selected[i] = items[c]
+	}
+	return selected, err
+}
 
-// hostFromURL removed (unused)
+// fzfLines formats items as fzf input lines.
+func fzfLines(items []api.Bookmark) []string {
+	lines := make([]string, len(items))
+	for i, b := range items {
+		shortHash := output.ShortHash(b.Hash)
+		tags := strings.Join(b.Tags, ",")
+		// Don't hard-truncate description; rely on preview wrapping for readability.
+		desc := b.Description
+		// Columns: position (1-based, as in the stored listing), shortHash, title, tags, description
+		lines[i] = fmt.Sprintf("%d\t%s\t%-40s\t%s\t%s", i+1, shortHash, sanitizeTabs(output.Truncate(b.Title, 40)), tags, sanitizeTabs(desc))
+	}
+	return lines
+}
 
-func copyToClipboard(text string) error {
-	switch runtime.GOOS {
-	case "darwin":
-		cmd := exec.Command("pbcopy")
-		stdin, _ := cmd.StdinPipe()
-		_ = cmd.Start()
-		stdin.Write([]byte(text))
+// pickActions are the fzf key bindings of pick with their default keys; [pickKeys] in
+// config.toml maps an action to another key, or to "none" to disable it.
+var pickActions = []struct{ name, key string }{
+	{"copy", "ctrl-y"},   // copy the hash and quit
+	{"edit", "ctrl-e"},   // markdex edit, then reload
+	{"delete", "ctrl-d"}, // markdex rm (asks first), then reload
+	{"tag", "ctrl-t"},    // narrow to bookmarks with the entry's first tag
+	{"reload", "ctrl-r"}, // reload from the server
+	{"ai", "ctrl-s"},     // toggle AI search mode: the query is sent to AI search as it is typed
+}
+
+// fzfBindings returns the --bind arguments for pick. self runs markdex against the same API;
+// reload returns the command that re-runs this pick printing its candidates, with extra flags.
+func fzfBindings(cfg *config.Config, self string, reload func(extra string) string) ([]string, error) {
+	for name := range cfg.PickKeys {
+		if !slices.ContainsFunc(pickActions, func(a struct{ name, key string }) bool { return a.name == name }) {
+			return nil, fmt.Errorf("pickKeys.%s: unknown action (valid: copy, edit, delete, tag, reload, ai)", name)
+		}
+	}
+	commands := map[string]string{
+		"copy":   "execute-silent(" + self + " _copy {1})+abort",
+		"edit":   "execute(" + self + " edit {1})+reload(" + reload("") + ")",
+		"delete": "execute(" + self + " rm {1})+reload(" + reload("") + ")",
+		"tag":    "reload(" + reload("--narrow-tag-of {1}") + ")",
+		"reload": "reload(" + reload("--no-cache") + ")",
+		"ai":     "transform(" + self + " _pick-mode)",
+	}
+	var args []string
+	for _, a := range pickActions {
+		key := firstNonEmpty(cfg.PickKeys[a.name], a.key)
+		if key == "none" {
+			continue
+		}
+		args = append(args, "--bind", key+":"+commands[a.name])
+		if a.name == "ai" {
+			// In AI mode every edit of the query searches again (see _pick-mode), once typing
+			// pauses: fzf stops a running reload when the next one starts, ending the sleep.
+			args = append(args, "--bind", "start:unbind(change)",
+				"--bind", "change:reload(sleep "+aiDebounce+"; "+reload("--ai-search {q}")+")")
+		}
+	}
+	return args, nil
+}
+
+// aiDebounce is how long AI search mode waits after a keystroke before searching.
+const aiDebounce = "0.4"
+
+// pickFzf lets the user choose among items with fzf; cmd and args are this pick's, for the
+// reload bindings.
+func pickFzf(cfg *config.Config, base, fzfPath string, items []api.Bookmark, cmd *cobra.Command, args []string) ([]api.Bookmark, error) {
+	lines := fzfLines(items)
+
+	// Show only short hash and title (cols 2,3)
+	fzfArgs := []string{"--with-nth", "2,3", "--delimiter", "\t", "--ansi", "--prompt", pickPrompt}
+	if pickFlagQuery != "" {
+		fzfArgs = append(fzfArgs, "--query", pickFlagQuery)
+	}
+	if pickFlagMulti {
+		fzfArgs = append(fzfArgs, "--multi")
+	}
+	fzfArgs = append(fzfArgs, strings.Fields(cfg.FzfOptions)...)
+	exe, err := os.Executable()
+	if err != nil {
+		return nil, err
+	}
+	// Bindings call back into markdex with the same API; bookmark text is never put into a
+	// shell command, only positions (column 1) and hashes (column 2) that fzf quotes.
+	self := util.ShellQuote(exe) + " --api " + util.ShellQuote(base)
+	reload := reloadCommand(exe, cmd, args)
+	fzfArgs = append(fzfArgs, "--preview", self+" _preview {1}")
+	bindings, err := fzfBindings(cfg, self, reload)
+	if err != nil {
+		return nil, err
+	}
+	fzfArgs = append(fzfArgs, bindings...)
+	cmdFzf := exec.Command(fzfPath, fzfArgs...)
+	cmdFzf.Env = append(os.Environ(), "MARKDEX_PICK_RELOAD="+reload(""), "MARKDEX_PICK_RELOAD_AI="+reload("--ai-search {q}"))
+	stdin, err := cmdFzf.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	cmdFzf.Stderr = os.Stderr
+	out, err := cmdFzf.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := cmdFzf.Start(); err != nil {
+		return nil, err
+	}
+	go func() {
+		w := bufio.NewWriter(stdin)
+		for _, l := range lines {
+			fmt.Fprintln(w, l)
+		}
+		w.Flush()
 		stdin.Close()
-		return cmd.Wait()
-	case "linux":
-		// try xclip then xsel
-		if _, err := exec.LookPath("xclip"); err == nil {
-			cmd := exec.Command("xclip", "-selection", "clipboard")
-			stdin, _ := cmd.StdinPipe()
-			_ = cmd.Start()
-			stdin.Write([]byte(text))
-			stdin.Close()
-			return cmd.Wait()
-		}
-		if _, err := exec.LookPath("xsel"); err == nil {
-			cmd := exec.Command("xsel", "--clipboard", "--input")
-			stdin, _ := cmd.StdinPipe()
-			_ = cmd.Start()
-			stdin.Write([]byte(text))
-			stdin.Close()
-			return cmd.Wait()
-		}
-	case "windows":
-		cmd := exec.Command("powershell", "-Command", "Set-Clipboard -Value @'"+text+"'@")
-		return cmd.Run()
-	}
-	return errors.New("clipboard utility not found")
+	}()
+
+	scanner := bufio.NewScanner(out)
+	var positions []int
+	for scanner.Scan() {
+		parts := strings.Split(scanner.Text(), "\t")
+		if len(parts) == 0 {
+			continue
+		}
+		var idx int
+		if _, err := fmt.Sscanf(parts[0], "%d", &idx); err == nil {
+			positions = append(positions, idx)
+		}
+	}
+	err = cmdFzf.Wait()
+	// If nothing selected, treat as normal cancel regardless of exit code.
+	if len(positions) == 0 {
+		return nil, nil
+	}
+	// If there was an error, ignore common fzf cancel/no-match codes (1, 130), otherwise return it.
+	if err != nil {
+		if ee, ok := err.(*exec.ExitError); ok {
+			code := ee.ExitCode()
+			if code == 1 || code == 130 { // 1=no match, 130=interrupted/ESC
+				return nil, nil
+			}
+		}
+		return nil, err
+	}
+	// Reload bindings replace the candidates and the stored listing, so positions refer
+	// to the latest listing rather than items.
+	if l, ok := cache.ReadListing(); ok {
+		items = l.Items
+	}
+	var selected []api.Bookmark
+	for _, p := range positions {
+		if p >= 1 && p <= len(items) {
+			selected = append(selected, items[p-1])
+		}
+	}
+	return selected, nil
+}
+
+// pickPrompt and aiPrompt are fzf's prompts in normal and AI search mode.
+const (
+	pickPrompt = "markdex> "
+	aiPrompt   = "ai> "
+)
+
+// pickModeCmd backs fzf's ai action: it prints the fzf actions that switch between fuzzy
+// filtering of the bookmarks and AI search results for the query, based on the current prompt.
+var pickModeCmd = &cobra.Command{
+	Use:    "_pick-mode",
+	Hidden: true,
+	Args:   cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		reload, reloadAI := os.Getenv("MARKDEX_PICK_RELOAD"), os.Getenv("MARKDEX_PICK_RELOAD_AI")
+		if reload == "" || reloadAI == "" {
+			return errors.New("_pick-mode runs from pick's fzf bindings")
+		}
+		if os.Getenv("FZF_PROMPT") == aiPrompt {
+			fmt.Printf("change-prompt(%s)+enable-search+unbind(change)+reload:%s", pickPrompt, reload)
+		} else {
+			fmt.Printf("change-prompt(%s)+disable-search+rebind(change)+reload:%s", aiPrompt, reloadAI)
+		}
+		return nil
+	},
+}
+
+// reloadCommand returns a function building the shell command that re-runs the pick cmd
+// with --emit-lines and extra flags. It is rebuilt from the parsed flags and args, so that
+// every flag comes before "--" and query words such as -tag:old stay arguments.
+func reloadCommand(exe string, cmd *cobra.Command, args []string) func(extra string) string {
+	var flags []string
+	cmd.Flags().Visit(func(f *pflag.Flag) {
+		switch f.Name {
+		case "emit-lines", "narrow-tag-of", "ai-search":
+			return
+		}
+		if sv, ok := f.Value.(pflag.SliceValue); ok {
+			for _, v := range sv.GetSlice() {
+				flags = append(flags, util.ShellQuote("--"+f.Name+"="+v))
+			}
+			return
+		}
+		flags = append(flags, util.ShellQuote("--"+f.Name+"="+f.Value.String()))
+	})
+	rest := append(flags, "--")
+	for _, a := range args {
+		rest = append(rest, util.ShellQuote(a))
+	}
+	return func(extra string) string {
+		parts := []string{util.ShellQuote(exe), cmd.Name(), "--emit-lines"}
+		if extra != "" {
+			parts = append(parts, extra)
+		}
+		return strings.Join(append(parts, rest...), " ")
+	}
+}
+
+func sanitizeTabs(s string) string { return strings.ReplaceAll(s, "\t", " ") }
+
+// copyCmd backs pick's ctrl-y binding: it copies the hash of the entry at a position of the
+// last listing with the configured clipboard tool. Copying a hash is not a use of the
+// bookmark, so unlike pick --copy it is not recorded.
+var copyCmd = &cobra.Command{
+	Use:    "_copy <index>",
+	Hidden: true,
+	Args:   cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, _ := config.Load()
+		base := firstNonEmpty(apiBase, cfg.APIBase)
+		items, err := fromListing(base, cfg, args)
+		if err != nil {
+			return err
+		}
+		return clipboard.Write(cfg.Clipboard, items[0].Hash)
+	},
 }
@@ -0,0 +1,98 @@
+package cmd
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/amaterasu/markdex-cli/internal/config"
+)
+
+func TestFzfBindings(t *testing.T) {
+	reload := func(extra string) string { return strings.TrimSpace("markdex pick --emit-lines " + extra) }
+	tests := []struct {
+		name string
+		keys map[string]string
+		want []string // --bind values, in order
+		err  string
+	}{
+		{
+			name: "defaults",
+			want: []string{
+				"ctrl-y:execute-silent(mx _copy {1})+abort",
+				"ctrl-e:execute(mx edit {1})+reload(markdex pick --emit-lines)",
+				"ctrl-d:execute(mx rm {1})+reload(markdex pick --emit-lines)",
+				"ctrl-t:reload(markdex pick --emit-lines --narrow-tag-of {1})",
+				"ctrl-r:reload(markdex pick --emit-lines --no-cache)",
+				"ctrl-s:transform(mx _pick-mode)",
+				"start:unbind(change)",
+				"change:reload(sleep 0.4; markdex pick --emit-lines --ai-search {q})",
+			},
+		},
+		{
+			name: "remapped and disabled",
+			keys: map[string]string{"copy": "none", "edit": "none", "delete": "none", "tag": "none", "reload": "alt-r", "ai": "alt-a"},
+			want: []string{
+				"alt-r:reload(markdex pick --emit-lines --no-cache)",
+				"alt-a:transform(mx _pick-mode)",
+				"start:unbind(change)",
+				"change:reload(sleep 0.4; markdex pick --emit-lines --ai-search {q})",
+			},
+		},
+		{
+			name: "no AI mode, no search on change",
+			keys: map[string]string{"copy": "none", "edit": "none", "delete": "none", "tag": "none", "reload": "none", "ai": "none"},
+		},
+		{
+			name: "unknown action",
+			keys: map[string]string{"open": "ctrl-o"},
+			err:  "pickKeys.open: unknown action (valid: copy, edit, delete, tag, reload, ai)",
+		},
+	}
+	for _, tt := range tests {
+		args, err := fzfBindings(&config.Config{PickKeys: tt.keys}, "mx", reload)
+		if tt.err != "" {
+			if err == nil || err.Error() != tt.err {
+				t.Errorf("%s: error = %v, want %q", tt.name, err, tt.err)
+			}
+			continue
+		}
+		var got []string
+		for i := 0; i+1 < len(args); i += 2 {
+			if args[i] != "--bind" {
+				t.Fatalf("%s: unexpected argument %q", tt.name, args[i])
+			}
+			got = append(got, args[i+1])
+		}
+		if strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+			t.Errorf("%s: bindings\n%s\nwant\n%s", tt.name, strings.Join(got, "\n"), strings.Join(tt.want, "\n"))
+		}
+	}
+}
+
+func TestPickMode(t *testing.T) {
+	t.Setenv("MARKDEX_PICK_RELOAD", "R")
+	t.Setenv("MARKDEX_PICK_RELOAD_AI", "RAI")
+	tests := []struct {
+		prompt, want string
+	}{
+		{pickPrompt, "change-prompt(ai> )+disable-search+rebind(change)+reload:RAI"},
+		{aiPrompt, "change-prompt(markdex> )+enable-search+unbind(change)+reload:R"},
+	}
+	for _, tt := range tests {
+		t.Setenv("FZF_PROMPT", tt.prompt)
+		r, w, _ := os.Pipe()
+		stdout := os.Stdout
+		os.Stdout = w
+		err := pickModeCmd.RunE(pickModeCmd, nil)
+		os.Stdout = stdout
+		w.Close()
+		var out bytes.Buffer
+		io.Copy(&out, r)
+		if err != nil || out.String() != tt.want {
+			t.Errorf("prompt %q: _pick-mode printed %q (%v), want %q", tt.prompt, out.String(), err, tt.want)
+		}
+	}
+}
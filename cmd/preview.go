@@ -0,0 +1,70 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/amaterasu/markdex-cli/internal/api"
+	"github.com/amaterasu/markdex-cli/internal/cache"
+	"github.com/amaterasu/markdex-cli/internal/output"
+)
+
+// previewCmd renders the fzf preview of pick. fzf passes the position of the highlighted
+// line, which is looked up in the listing pick stored, so bookmark text never goes through
+// a shell.
+var previewCmd = &cobra.Command{
+	Use:    "_preview <index|hash>",
+	Hidden: true,
+	Args:   cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		b, err := previewItem(args[0])
+		if err != nil {
+			return err
+		}
+		width, _ := strconv.Atoi(os.Getenv("FZF_PREVIEW_COLUMNS"))
+		if width <= 0 {
+			width = firstPositive(output.DetectTerm(os.Stdout).Width, 80)
+		}
+		t := output.Term{Color: os.Getenv("NO_COLOR") == ""}
+		fmt.Println(strings.Join(output.Preview(b, width, t), "\n"))
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(previewCmd)
+}
+
+// previewItem finds the bookmark at a 1-based position of the last listing, or by hash
+// prefix in the bookmark cache.
+func previewItem(arg string) (api.Bookmark, error) {
+	if n, err := strconv.Atoi(arg); err == nil {
+		l, ok := cache.ReadListing()
+		if !ok || n < 1 || n > len(l.Items) {
+			return api.Bookmark{}, fmt.Errorf("no entry %d in the last listing", n)
+		}
+		return l.Items[n-1], nil
+	}
+	items, ok := cache.New(0).ReadStale()
+	if !ok {
+		return api.Bookmark{}, errors.New("no cached bookmarks")
+	}
+	return findByPrefix(items, arg)
+}
+
+func firstPositive(xs ...int) int {
+	for _, x := range xs {
+		if x > 0 {
+			return x
+		}
+	}
+	return 0
+}
+
+// flattenLine joins a multi-line value into one line.
+func flattenLine(s string) string { return strings.Join(strings.Fields(s), " ") }
@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/amaterasu/markdex-cli/internal/api"
+	"github.com/amaterasu/markdex-cli/internal/cache"
+)
+
+func TestPreviewItem(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	t.Setenv("HOME", t.TempDir())
+	if _, err := previewItem("1"); err == nil {
+		t.Error("previewItem with no listing: no error")
+	}
+	if _, err := previewItem("abc"); err == nil {
+		t.Error("previewItem with no cache: no error")
+	}
+
+	listed := []api.Bookmark{{Hash: "abc111", Title: "First"}, {Hash: "def222", Title: "Second"}}
+	cache.WriteListing("", listed)
+	cache.New(0).Write(append(listed, api.Bookmark{Hash: "abd333", Title: "Unlisted"}))
+	tests := []struct {
+		arg   string
+		title string // "" for an error
+	}{
+		{"1", "First"},
+		{"2", "Second"},
+		{"0", ""},
+		{"3", ""},
+		{"def", "Second"},
+		{"abd", "Unlisted"},
+		{"ab", ""}, // ambiguous
+		{"fff", ""},
+	}
+	for _, tt := range tests {
+		b, err := previewItem(tt.arg)
+		if tt.title == "" {
+			if err == nil {
+				t.Errorf("previewItem(%q) = %q, want an error", tt.arg, b.Title)
+			}
+			continue
+		}
+		if err != nil || b.Title != tt.title {
+			t.Errorf("previewItem(%q) = %q, %v; want %q", tt.arg, b.Title, err, tt.title)
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/template"
+
+	"github.com/amaterasu/markdex-cli/internal/api"
+	"github.com/amaterasu/markdex-cli/internal/cache"
+	"github.com/amaterasu/markdex-cli/internal/config"
+	"github.com/amaterasu/markdex-cli/internal/output"
+)
+
+var (
+	outFormat   output.Format
+	outTemplate *template.Template
+)
+
+// setupOutput resolves --output and --format once per invocation. A --format value without
+// template actions is looked up among the templates saved in config.
+func setupOutput(cfg *config.Config) error {
+	f, err := output.Parse(outputFlag)
+	if err != nil {
+		return err
+	}
+	outFormat = f
+	if formatFlag == "" {
+		return nil
+	}
+	text := formatFlag
+	if !strings.Contains(text, "{{") {
+		named, ok := cfg.Templates[strings.ToLower(text)]
+		if !ok {
+			return fmt.Errorf("--format: no template named %q (save one with markdex config set templates.%s '<template>')", text, text)
+		}
+		text = named
+	}
+	t, err := output.ParseTemplate(text)
+	if err != nil {
+		return fmt.Errorf("--format: %w", err)
+	}
+	outTemplate = t
+	return nil
+}
+
+// format returns the selected output format; legacy --json flags force JSON.
+func format(jsonFlag bool) output.Format {
+	if jsonFlag {
+		return output.JSON
+	}
+	return outFormat
+}
+
+// indexHashes prepares the shortest unique hash prefixes for display. Uniqueness is computed
+// across the whole cached collection so a shown prefix also works with markdex open, not
+// just within a filtered listing; without a cache the prefixes are SafeHashPrefix long.
+func indexHashes(items []api.Bookmark) {
+	all, minLen := items, output.SafeHashPrefix
+	if cached, ok := cache.New(0).ReadStale(); ok {
+		all, minLen = append(cached, items...), output.MinHashPrefix
+	}
+	hashes := make([]string, 0, len(all))
+	seen := map[string]bool{}
+	for _, b := range all {
+		if !seen[b.Hash] {
+			seen[b.Hash] = true
+			hashes = append(hashes, b.Hash)
+		}
+	}
+	output.IndexHashes(hashes, minLen)
+}
+
+// printBookmarks writes a listing with the --format template if given, else in the selected format.
+func printBookmarks(items []api.Bookmark, jsonFlag bool) error {
+	indexHashes(items)
+	if outTemplate != nil {
+		return output.Execute(os.Stdout, outTemplate, items)
+	}
+	return output.Bookmarks(os.Stdout, format(jsonFlag), items)
+}
+
+// printGroups writes grouped listings; a --format template is applied to each bookmark in group order.
+func printGroups(groups []output.Group, jsonFlag bool) error {
+	all := flattenGroups(groups)
+	indexHashes(all)
+	if outTemplate != nil {
+		return output.Execute(os.Stdout, outTemplate, all)
+	}
+	return output.Groups(os.Stdout, format(jsonFlag), groups)
+}
+
+// flattenGroups returns the bookmarks of all groups in display order.
+func flattenGroups(groups []output.Group) []api.Bookmark {
+	var all []api.Bookmark
+	for _, g := range groups {
+		all = append(all, g.Items...)
+	}
+	return all
+}
+
+// printBookmark is printBookmarks for a single bookmark.
+func printBookmark(b api.Bookmark, jsonFlag bool) error {
+	indexHashes([]api.Bookmark{b})
+	if outTemplate != nil {
+		return output.Execute(os.Stdout, outTemplate, b)
+	}
+	return output.Bookmark(os.Stdout, format(jsonFlag), b)
+}
+
+// printRows writes other listings (e.g. tags): v is rendered by structured formats and
+// templates, t by the tabular ones.
+func printRows(v any, t output.Tabular) error {
+	if outTemplate != nil {
+		return output.Execute(os.Stdout, outTemplate, v)
+	}
+	return output.Render(os.Stdout, outFormat, v, t)
+}
@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/amaterasu/markdex-cli/internal/api"
+	"github.com/amaterasu/markdex-cli/internal/config"
+)
+
+// currentProject returns the .markdex.toml settings for the working directory, or nil if there are none.
+func currentProject() (*config.Project, error) {
+	wd, err := os.Getwd()
+	if err != nil {
+		return nil, err
+	}
+	return config.FindProject(wd)
+}
+
+// requireProject is like currentProject but fails when no project file exists (used by --here).
+func requireProject() (*config.Project, error) {
+	p, err := currentProject()
+	if err != nil {
+		return nil, err
+	}
+	if p == nil {
+		return nil, fmt.Errorf("--here: no %s found in this directory or its parents", config.ProjectFile)
+	}
+	if p.Scope.Empty() {
+		return nil, fmt.Errorf("--here: %s defines no scope or tags", p.Path)
+	}
+	return p, nil
+}
+
+// filterScope keeps the bookmarks that fall inside the project scope.
+func filterScope(items []api.Bookmark, s config.Scope) []api.Bookmark {
+	out := items[:0:0]
+	for _, b := range items {
+		if inScope(b, s) {
+			out = append(out, b)
+		}
+	}
+	return out
+}
+
+func inScope(b api.Bookmark, s config.Scope) bool {
+	if len(s.Tags) > 0 && !anyFold(b.Tags, s.Tags) {
+		return false
+	}
+	if len(s.SourceFiles) > 0 && !anyFold([]string{b.SourceFile}, s.SourceFiles) {
+		return false
+	}
+	if len(s.Sections) > 0 && !anyFold([]string{b.Section}, s.Sections) {
+		return false
+	}
+	return true
+}
+
+// anyFold reports whether any element of have equals (case-insensitively) an element of want.
+func anyFold(have, want []string) bool {
+	for _, h := range have {
+		for _, w := range want {
+			if strings.EqualFold(h, w) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// mergeTags appends extra tags not already present (case-insensitive), preserving order.
+func mergeTags(tags, extra []string) []string {
+	for _, t := range extra {
+		if !anyFold(tags, []string{t}) {
+			tags = append(tags, t)
+		}
+	}
+	return tags
+}
@@ -6,11 +6,17 @@ import (
 
 	"github.com/fatih/color"
 	"github.com/spf13/cobra"
+
+	"github.com/amaterasu/markdex-cli/internal/api"
+	"github.com/amaterasu/markdex-cli/internal/config"
+	"github.com/amaterasu/markdex-cli/internal/output"
 )
 
 var (
-	cfgFile string
-	apiBase string
+	cfgFile    string
+	apiBase    string
+	outputFlag string
+	formatFlag string
 )
 
 // These are set via -ldflags at build time.
@@ -24,6 +30,18 @@ var rootCmd = &cobra.Command{
 	Use:   "markdex",
 	Short: "Markdex CLI - interact with your bookmarks",
 	Long:  "Markdex CLI provides fast access to listing, searching, and opening bookmarks.",
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		cfg, _ := config.Load()
+		api.SetTimeout(cfg.Timeout)
+		return setupOutput(cfg)
+	},
+	PersistentPostRun: func(cmd *cobra.Command, args []string) {
+		// deliver usage queued while the server was unreachable once it answers again
+		if api.Reached() && cmd != syncCmd {
+			cfg, _ := config.Load()
+			flushUsage(firstNonEmpty(apiBase, cfg.APIBase))
+		}
+	},
 }
 
 func Execute() {
@@ -35,8 +53,9 @@ func Execute() {
 
 func init() {
 	rootCmd.PersistentFlags().StringVar(&apiBase, "api", "", "API base URL (overrides config)")
+	rootCmd.PersistentFlags().StringVarP(&outputFlag, "output", "o", "table", "Output format: "+output.Names())
+	rootCmd.PersistentFlags().StringVar(&formatFlag, "format", "", "Go template applied to each result, or the name of a template saved in config (e.g. '{{short .Hash}} {{.URL}}')")
 	rootCmd.AddCommand(listCmd)
-	//rootCmd.AddCommand(openCmd)
 	rootCmd.AddCommand(configCmd)
 	rootCmd.AddCommand(pickCmd)
 	rootCmd.AddCommand(addCmd)
@@ -45,4 +64,5 @@ func init() {
 	}})
 }
 
-func infof(format string, a ...any) { color.New(color.FgHiBlack).Printf(format+"\n", a...) }
+// infof prints a dimmed informational note on stderr, apart from the command's output.
+func infof(format string, a ...any) { color.New(color.FgHiBlack).Fprintf(os.Stderr, format+"\n", a...) }
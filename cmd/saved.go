@@ -0,0 +1,98 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/amaterasu/markdex-cli/internal/config"
+	"github.com/amaterasu/markdex-cli/internal/output"
+	"github.com/amaterasu/markdex-cli/internal/query"
+)
+
+var savedFlagSort string
+
+type savedRow struct {
+	Name               string `json:"name" yaml:"name"`
+	config.SavedSearch `yaml:",inline"`
+}
+
+var savedCmd = &cobra.Command{
+	Use:   "saved",
+	Short: "Manage saved searches (use them as @name with list, pick and open)",
+}
+
+var savedAddCmd = &cobra.Command{
+	Use:   "add <name> <query...>",
+	Short: "Save a query under a name, optionally with a default --sort and --output",
+	Long:  "Save a query under a name, e.g. 'markdex saved add work tag:work site:github.com --sort usage -o json'. Afterwards 'markdex list @work' runs it with those defaults (explicit flags still win).",
+	Args:  cobra.MinimumNArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name := strings.ToLower(args[0])
+		text := joinQuery(args[1:])
+		if _, err := query.Parse(text); err != nil {
+			return err
+		}
+		if savedFlagSort != "" && !validSort(savedFlagSort) {
+			return fmt.Errorf("invalid --sort %q (valid: %s)", savedFlagSort, strings.Join(sortKeys, ", "))
+		}
+		s := config.SavedSearch{Query: text, Sort: savedFlagSort}
+		if cmd.Flags().Changed("output") {
+			s.Output = string(outFormat)
+		}
+		if err := config.SaveSearch(name, s); err != nil {
+			return err
+		}
+		fmt.Printf("Saved search @%s\n", name)
+		return nil
+	},
+}
+
+var savedListCmd = &cobra.Command{
+	Use:     "list",
+	Aliases: []string{"ls"},
+	Short:   "List saved searches",
+	Args:    cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, _ := config.Load()
+		rows := make([]savedRow, 0, len(cfg.Saved))
+		for name, s := range cfg.Saved {
+			rows = append(rows, savedRow{Name: name, SavedSearch: s})
+		}
+		sort.Slice(rows, func(i, j int) bool { return rows[i].Name < rows[j].Name })
+		t := output.Tabular{Header: []string{"NAME", "QUERY", "SORT", "OUTPUT"}}
+		for _, r := range rows {
+			t.Rows = append(t.Rows, []string{"@" + r.Name, r.Query, r.Sort, r.Output})
+		}
+		if len(rows) == 0 && outFormat == output.Table && outTemplate == nil {
+			fmt.Println("No saved searches (add one with markdex saved add <name> <query>).")
+			return nil
+		}
+		return printRows(rows, t)
+	},
+}
+
+var savedRmCmd = &cobra.Command{
+	Use:     "rm <name>",
+	Aliases: []string{"remove"},
+	Short:   "Delete a saved search",
+	Args:    cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name := strings.TrimPrefix(args[0], "@")
+		if err := config.RemoveSearch(name); err != nil {
+			return err
+		}
+		fmt.Printf("Removed saved search @%s\n", strings.ToLower(name))
+		return nil
+	},
+}
+
+func init() {
+	savedAddCmd.Flags().StringVar(&savedFlagSort, "sort", "", "Default sort for this search: "+strings.Join(sortKeys, ", "))
+	savedCmd.AddCommand(savedAddCmd)
+	savedCmd.AddCommand(savedListCmd)
+	savedCmd.AddCommand(savedRmCmd)
+	rootCmd.AddCommand(savedCmd)
+}
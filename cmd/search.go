@@ -1,11 +1,8 @@
 package cmd
 
 import (
-	"encoding/json"
 	"errors"
 	"fmt"
-	"os"
-	"sort"
 	"strings"
 
 	"github.com/spf13/cobra"
@@ -36,25 +33,13 @@ var searchCmd = &cobra.Command{
 		if err != nil {
 			return err
 		}
-		// sort deterministically by title
-		sort.Slice(items, func(i, j int) bool { return strings.ToLower(items[i].Title) < strings.ToLower(items[j].Title) })
-		if searchJSON {
-			enc := json.NewEncoder(os.Stdout)
-			enc.SetIndent("", "  ")
-			return enc.Encode(items)
-		}
-		if len(items) == 0 {
-			fmt.Println("No matching entries found.")
-			return nil
-		}
-		for _, b := range items {
-			fmt.Printf("%s  %-40s  %s\n", b.Hash[:7], truncate(b.Title, 40), b.Tags)
-		}
-		return nil
+		sortBookmarks(items, cfg.DefaultSort)
+		rememberListing(base, cfg, items)
+		return printBookmarks(items, searchJSON)
 	},
 }
 
 func init() {
-	searchCmd.Flags().BoolVar(&searchJSON, "json", false, "Output JSON")
+	searchCmd.Flags().BoolVar(&searchJSON, "json", false, "Output JSON (same as --output json)")
 	rootCmd.AddCommand(searchCmd)
 }
@@ -0,0 +1,38 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/amaterasu/markdex-cli/internal/config"
+)
+
+var showFlagNoCache bool
+
+var showCmd = &cobra.Command{
+	Use:   "show <hash-prefix>",
+	Short: "Show all fields of a bookmark by its hash prefix",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, _ := config.Load()
+		base := firstNonEmpty(apiBase, cfg.APIBase)
+		if base == "" {
+			return fmt.Errorf("API base not set")
+		}
+		items, err := loadBookmarks(base, cfg, showFlagNoCache)
+		if err != nil {
+			return err
+		}
+		b, err := findByPrefix(items, args[0])
+		if err != nil {
+			return err
+		}
+		return printBookmark(b, false)
+	},
+}
+
+func init() {
+	showCmd.Flags().BoolVar(&showFlagNoCache, "no-cache", false, "Bypass local cache")
+	rootCmd.AddCommand(showCmd)
+}
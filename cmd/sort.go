@@ -0,0 +1,152 @@
+package cmd
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/amaterasu/markdex-cli/internal/api"
+	"github.com/amaterasu/markdex-cli/internal/history"
+	"github.com/amaterasu/markdex-cli/internal/output"
+)
+
+// sortKeys are the values accepted by --sort (and the defaultSort config key).
+var sortKeys = []string{"title", "usage", "frecency", "url", "host", "source", "section"}
+
+// groupKeys are the values accepted by --group-by.
+var groupKeys = []string{"tag", "host", "section", "source-file"}
+
+func validSort(by string) bool { return contains(sortKeys, by) }
+
+func validGroup(by string) bool { return contains(groupKeys, by) }
+
+func contains(xs []string, x string) bool {
+	for _, v := range xs {
+		if v == x {
+			return true
+		}
+	}
+	return false
+}
+
+// sortBookmarks orders items in place by the given key; usage and frecency sort the most
+// used first and every key falls back to the title so the order is deterministic.
+func sortBookmarks(items []api.Bookmark, by string) {
+	title := func(i, j int) bool { return strings.ToLower(items[i].Title) < strings.ToLower(items[j].Title) }
+	byString := func(key func(b api.Bookmark) string) func(i, j int) bool {
+		return func(i, j int) bool {
+			ki, kj := key(items[i]), key(items[j])
+			if ki != kj {
+				return ki < kj
+			}
+			return title(i, j)
+		}
+	}
+	var less func(i, j int) bool
+	switch by {
+	case "usage":
+		less = func(i, j int) bool {
+			if items[i].Usage != items[j].Usage {
+				return items[i].Usage > items[j].Usage
+			}
+			return title(i, j)
+		}
+	case "frecency":
+		scores := frecencyScores(items)
+		less = func(i, j int) bool {
+			if si, sj := scores[items[i].Hash], scores[items[j].Hash]; si != sj {
+				return si > sj
+			}
+			return title(i, j)
+		}
+	case "url":
+		less = byString(func(b api.Bookmark) string { return b.URL })
+	case "host":
+		less = byString(func(b api.Bookmark) string { return strings.ToLower(output.Host(b.URL)) })
+	case "section":
+		less = byString(func(b api.Bookmark) string { return strings.ToLower(b.Section) })
+	case "source":
+		less = func(i, j int) bool {
+			if items[i].SourceFile != items[j].SourceFile {
+				return items[i].SourceFile < items[j].SourceFile
+			}
+			if items[i].Line != items[j].Line {
+				return items[i].Line < items[j].Line
+			}
+			return title(i, j)
+		}
+	default:
+		less = title
+	}
+	sort.SliceStable(items, less)
+}
+
+func reverseBookmarks(items []api.Bookmark) {
+	for i, j := 0, len(items)-1; i < j; i, j = i+1, j-1 {
+		items[i], items[j] = items[j], items[i]
+	}
+}
+
+// groupBookmarks splits items into named groups, keeping the order of items within each
+// group. Groups are ordered by name with the catch-all group (no tag, host, ...) last.
+// With "tag" a bookmark appears under each of its tags.
+func groupBookmarks(items []api.Bookmark, by string) []output.Group {
+	var none string
+	var keys func(b api.Bookmark) []string
+	switch by {
+	case "tag":
+		none = "(untagged)"
+		keys = func(b api.Bookmark) []string {
+			tags := make([]string, len(b.Tags))
+			for i, t := range b.Tags {
+				tags[i] = strings.ToLower(t)
+			}
+			return tags
+		}
+	case "host":
+		none = "(no host)"
+		keys = func(b api.Bookmark) []string { return []string{strings.ToLower(output.Host(b.URL))} }
+	case "section":
+		none = "(no section)"
+		keys = func(b api.Bookmark) []string { return []string{b.Section} }
+	default: // source-file
+		none = "(no source file)"
+		keys = func(b api.Bookmark) []string { return []string{b.SourceFile} }
+	}
+
+	index := map[string]int{}
+	var groups []output.Group
+	for _, b := range items {
+		names := keys(b)
+		if len(names) == 0 || (len(names) == 1 && names[0] == "") {
+			names = []string{none}
+		}
+		for _, n := range names {
+			i, ok := index[n]
+			if !ok {
+				i = len(groups)
+				index[n] = i
+				groups = append(groups, output.Group{Name: n})
+			}
+			groups[i].Items = append(groups[i].Items, b)
+		}
+	}
+	sort.SliceStable(groups, func(i, j int) bool {
+		if (groups[i].Name == none) != (groups[j].Name == none) {
+			return groups[j].Name == none
+		}
+		return strings.ToLower(groups[i].Name) < strings.ToLower(groups[j].Name)
+	})
+	return groups
+}
+
+// frecencyScores combines the local open history with the server's usage counts
+// (Usage and, when present, TotalUsage).
+func frecencyScores(items []api.Bookmark) map[string]float64 {
+	entries, _ := history.Load()
+	scores := history.Frecency(entries, time.Now())
+	for _, b := range items {
+		scores[b.Hash] += history.UsageWeight(b.Usage, b.TotalUsage)
+	}
+	return scores
+}
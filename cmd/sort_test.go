@@ -0,0 +1,98 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/amaterasu/markdex-cli/internal/api"
+	"github.com/amaterasu/markdex-cli/internal/history"
+)
+
+var sortSample = []api.Bookmark{
+	{Hash: "a", Title: "beta", URL: "https://WWW.Example.com/b", Usage: 2, Section: "Go", SourceFile: "b.md", Line: 3, Tags: []string{"Go", "web"}},
+	{Hash: "b", Title: "Alpha", URL: "https://blog.example.org/", Usage: 5, SourceFile: "a.md", Line: 9},
+	{Hash: "c", Title: "gamma", URL: "https://www.example.com/a", Usage: 2, Section: "go", SourceFile: "b.md", Line: 1, Tags: []string{"web"}},
+	{Hash: "d", Title: "delta", URL: "not a url", Section: "Rust", Tags: []string{"go"}},
+}
+
+func order(items []api.Bookmark) string {
+	var hs []string
+	for _, b := range items {
+		hs = append(hs, b.Hash)
+	}
+	return strings.Join(hs, "")
+}
+
+func TestSortBookmarks(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir()) // no open history
+	tests := []struct {
+		by   string
+		want string
+	}{
+		{"", "badc"},
+		{"title", "badc"},
+		{"usage", "bacd"}, // ties by title
+		{"frecency", "bacd"},
+		{"url", "abcd"},  // as written: upper case first
+		{"host", "dbac"}, // hosts compare case-insensitively, then titles
+		{"section", "bacd"},
+		{"source", "dbca"},
+	}
+	for _, tt := range tests {
+		items := append([]api.Bookmark(nil), sortSample...)
+		sortBookmarks(items, tt.by)
+		if got := order(items); got != tt.want {
+			t.Errorf("sort by %q = %s, want %s", tt.by, got, tt.want)
+		}
+	}
+
+	items := append([]api.Bookmark(nil), sortSample...)
+	sortBookmarks(items, "usage")
+	reverseBookmarks(items)
+	if got := order(items); got != "dcab" {
+		t.Errorf("reversed usage order = %s, want dcab", got)
+	}
+}
+
+func TestGroupBookmarks(t *testing.T) {
+	tests := []struct {
+		by   string
+		want string
+	}{
+		{"tag", "go: a,d; web: a,c; (untagged): b"},
+		{"host", "blog.example.org: b; www.example.com: a,c; (no host): d"},
+		{"section", "Go: a; go: c; Rust: d; (no section): b"},
+		{"source-file", "a.md: b; b.md: a,c; (no source file): d"},
+	}
+	for _, tt := range tests {
+		var parts []string
+		for _, g := range groupBookmarks(sortSample, tt.by) {
+			var hs []string
+			for _, b := range g.Items {
+				hs = append(hs, b.Hash)
+			}
+			parts = append(parts, fmt.Sprintf("%s: %s", g.Name, strings.Join(hs, ",")))
+		}
+		if got := strings.Join(parts, "; "); got != tt.want {
+			t.Errorf("group by %s = %q, want %q", tt.by, got, tt.want)
+		}
+	}
+	if groups := groupBookmarks(nil, "tag"); len(groups) != 0 {
+		t.Errorf("groups of nothing = %v", groups)
+	}
+}
+
+func TestSortByFrecency(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	// delta was never used on the server but was opened here recently; alpha has the most
+	// server usage; gamma was opened long ago
+	history.Record(history.Entry{Hash: "d"})
+	history.Record(history.Entry{Hash: "c", Time: time.Now().Add(-200 * 24 * time.Hour)})
+	items := append([]api.Bookmark(nil), sortSample...)
+	sortBookmarks(items, "frecency")
+	if got := order(items); got != "dbca" {
+		t.Errorf("frecency order = %s, want dbca", got)
+	}
+}
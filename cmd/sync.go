@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+
+	"github.com/spf13/cobra"
+
+	"github.com/amaterasu/markdex-cli/internal/api"
+	"github.com/amaterasu/markdex-cli/internal/config"
+	"github.com/amaterasu/markdex-cli/internal/queue"
+)
+
+var syncCmd = &cobra.Command{
+	Use:   "sync",
+	Short: "Send usage events queued while the server was unreachable",
+	Long:  "open and pick report each use to the server. Reports that fail because the server is unreachable are queued locally and sent with the next successful request; sync sends them now.",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, _ := config.Load()
+		base := firstNonEmpty(apiBase, cfg.APIBase)
+		if base == "" {
+			return fmt.Errorf("API base not set (use markdex config set --api <url>)")
+		}
+		sent, left, err := flushUsage(base)
+		if sent > 0 || left > 0 {
+			infof("Sent %d queued usage event(s), %d still queued", sent, left)
+		} else if err == nil {
+			infof("Nothing to sync")
+		}
+		return err
+	},
+}
+
+// flushUsage sends the usage events queued for base, in one batch when the server
+// supports it and one by one otherwise. Events the server rejects are dropped.
+func flushUsage(base string) (sent, left int, err error) {
+	return queue.Flush(base, func(events []queue.Event) (int, error) {
+		reqs := make([]api.UsageRequest, len(events))
+		for i, e := range events {
+			reqs[i] = api.UsageRequest{Hash: e.Hash, UserId: e.UserID}
+		}
+		err := api.UseBookmarks(base, reqs)
+		if err == nil {
+			return len(events), nil
+		}
+		if !errors.Is(err, api.ErrBatchUnsupported) && api.Retryable(err) {
+			return 0, err
+		}
+		// no batch endpoint, or the batch was rejected: send one by one so that only
+		// the events the server refuses are dropped
+		for i, r := range reqs {
+			q := url.Values{}
+			q.Set("hash", r.Hash)
+			q.Set("user_id", r.UserId)
+			if _, err := api.UseBookmark(base, q); api.Retryable(err) {
+				return i, err
+			}
+		}
+		return len(events), nil
+	})
+}
+
+func init() {
+	rootCmd.AddCommand(syncCmd)
+}
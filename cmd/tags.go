@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/amaterasu/markdex-cli/internal/config"
+	"github.com/amaterasu/markdex-cli/internal/output"
+)
+
+var tagsFlagNoCache bool
+
+type tagCount struct {
+	Tag   string `json:"tag" yaml:"tag"`
+	Count int    `json:"count" yaml:"count"`
+}
+
+var tagsCmd = &cobra.Command{
+	Use:   "tags",
+	Short: "List tags with the number of bookmarks using each",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, _ := config.Load()
+		base := firstNonEmpty(apiBase, cfg.APIBase)
+		if base == "" {
+			return fmt.Errorf("API base not set (use markdex config set --api <url>)")
+		}
+		items, err := loadBookmarks(base, cfg, tagsFlagNoCache)
+		if err != nil {
+			return err
+		}
+		counts := map[string]int{}
+		for _, b := range items {
+			for _, t := range b.Tags {
+				counts[strings.ToLower(t)]++
+			}
+		}
+		tags := make([]tagCount, 0, len(counts))
+		for t, n := range counts {
+			tags = append(tags, tagCount{Tag: t, Count: n})
+		}
+		// most used first, then alphabetical
+		sort.Slice(tags, func(i, j int) bool {
+			if tags[i].Count != tags[j].Count {
+				return tags[i].Count > tags[j].Count
+			}
+			return tags[i].Tag < tags[j].Tag
+		})
+		t := output.Tabular{Header: []string{"TAG", "COUNT"}}
+		for _, tc := range tags {
+			t.Rows = append(t.Rows, []string{tc.Tag, strconv.Itoa(tc.Count)})
+		}
+		return printRows(tags, t)
+	},
+}
+
+func init() {
+	tagsCmd.Flags().BoolVar(&tagsFlagNoCache, "no-cache", false, "Bypass local cache")
+	rootCmd.AddCommand(tagsCmd)
+}
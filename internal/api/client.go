@@ -6,21 +6,24 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
 	"net/url"
+	"sync/atomic"
 	"time"
 )
 
 type Bookmark struct {
-	Title       string   `json:"title"`
-	URL         string   `json:"url"`
-	Description string   `json:"description,omitempty"`
-	Tags        []string `json:"tags,omitempty"`
-	Section     string   `json:"section,omitempty"`
-	Hash        string   `json:"hash,omitempty"`
-	SourceFile  string   `json:"source_file,omitempty"`
-	Line        int      `json:"line,omitempty"`
-	Usage       int      `json:"usage,omitempty"`
+	Title       string   `json:"title" yaml:"title"`
+	URL         string   `json:"url" yaml:"url"`
+	Description string   `json:"description,omitempty" yaml:"description,omitempty"`
+	Tags        []string `json:"tags,omitempty" yaml:"tags,omitempty"`
+	Section     string   `json:"section,omitempty" yaml:"section,omitempty"`
+	Hash        string   `json:"hash,omitempty" yaml:"hash,omitempty"`
+	SourceFile  string   `json:"source_file,omitempty" yaml:"source_file,omitempty"`
+	Line        int      `json:"line,omitempty" yaml:"line,omitempty"`
+	Usage       int      `json:"usage,omitempty" yaml:"usage,omitempty"`
+	TotalUsage  int      `json:"total_usage,omitempty" yaml:"total_usage,omitempty"` // all users, when the API reports it
 }
 
 type Usage struct {
@@ -42,6 +45,47 @@ type UsageRequest struct {
 
 var httpClient = &http.Client{Timeout: 12 * time.Second}
 
+// reached records whether any request got an answer from the server in this process.
+var reached atomic.Bool
+
+// do sends req with the shared client.
+func do(req *http.Request) (*http.Response, error) {
+	resp, err := httpClient.Do(req)
+	if err == nil && resp.StatusCode < 500 {
+		reached.Store(true)
+	}
+	return resp, err
+}
+
+// Reached reports whether the server answered any request made so far.
+func Reached() bool { return reached.Load() }
+
+// StatusError is returned when the server answers with an HTTP error status.
+type StatusError struct {
+	Code int
+}
+
+func (e *StatusError) Error() string { return fmt.Sprintf("http %d", e.Code) }
+
+// Retryable reports whether a request failing with err may succeed later: the server was
+// unreachable (a transport error), overloaded or failed (5xx, 429, 408), as opposed to
+// rejecting the request or answering in an unexpected form.
+func Retryable(err error) bool {
+	var se *StatusError
+	if errors.As(err, &se) {
+		return se.Code >= 500 || se.Code == http.StatusTooManyRequests || se.Code == http.StatusRequestTimeout
+	}
+	var ne net.Error
+	return errors.As(err, &ne)
+}
+
+// SetTimeout changes the timeout used for all API requests (ignored if d is not positive).
+func SetTimeout(d time.Duration) {
+	if d > 0 {
+		httpClient.Timeout = d
+	}
+}
+
 // CreateBookmarkRequest represents the POST body for creating a bookmark.
 type CreateBookmarkRequest struct {
 	URL         string   `json:"url"`
@@ -65,13 +109,13 @@ func CreateBookmark(base string, req CreateBookmarkRequest) (Bookmark, error) {
 	}
 	httpReq.Header.Set("Content-Type", "application/json")
 	httpReq.Header.Set("Accept", "application/json")
-	resp, err := httpClient.Do(httpReq)
+	resp, err := do(httpReq)
 	if err != nil {
 		return Bookmark{}, err
 	}
 	defer resp.Body.Close()
 	if resp.StatusCode >= 400 {
-		return Bookmark{}, fmt.Errorf("http %d", resp.StatusCode)
+		return Bookmark{}, &StatusError{Code: resp.StatusCode}
 	}
 	b, err := io.ReadAll(resp.Body)
 	if err != nil {
@@ -84,6 +128,66 @@ func CreateBookmark(base string, req CreateBookmarkRequest) (Bookmark, error) {
 	return bk, nil
 }
 
+// UpdateBookmarkRequest is the PATCH body for changing a bookmark; only set fields change.
+type UpdateBookmarkRequest struct {
+	Title       *string   `json:"title,omitempty"`
+	URL         *string   `json:"url,omitempty"`
+	Tags        *[]string `json:"tags,omitempty"`
+	Description *string   `json:"description,omitempty"`
+	Section     *string   `json:"section,omitempty"`
+	SourceFile  *string   `json:"source_file,omitempty"`
+}
+
+// UpdateBookmark changes the bookmark with the given hash via PATCH /api/bookmarks/{hash}
+// and returns the updated bookmark.
+func UpdateBookmark(base, hash string, req UpdateBookmarkRequest) (Bookmark, error) {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return Bookmark{}, err
+	}
+	httpReq, err := http.NewRequest("PATCH", base+"/api/bookmarks/"+url.PathEscape(hash), bytes.NewBuffer(body))
+	if err != nil {
+		return Bookmark{}, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("Accept", "application/json")
+	resp, err := do(httpReq)
+	if err != nil {
+		return Bookmark{}, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 400 {
+		return Bookmark{}, &StatusError{Code: resp.StatusCode}
+	}
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return Bookmark{}, err
+	}
+	var bk Bookmark
+	if err := json.Unmarshal(b, &bk); err != nil {
+		return Bookmark{}, fmt.Errorf("failed to decode response: %w", err)
+	}
+	return bk, nil
+}
+
+// DeleteBookmark removes the bookmark with the given hash via DELETE /api/bookmarks/{hash}.
+func DeleteBookmark(base, hash string) error {
+	req, err := http.NewRequest("DELETE", base+"/api/bookmarks/"+url.PathEscape(hash), nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 400 {
+		return &StatusError{Code: resp.StatusCode}
+	}
+	return nil
+}
+
 func UseBookmark(base string, q url.Values) (Usage, error) {
 
 	endpoint := base + "/api/usage"
@@ -106,14 +210,14 @@ func UseBookmark(base string, q url.Values) (Usage, error) {
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Accept", "application/json")
 
-	resp, err := httpClient.Do(req)
+	resp, err := do(req)
 	if err != nil {
 		return Usage{}, err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode >= 400 {
-		return Usage{}, fmt.Errorf("http %d", resp.StatusCode)
+		return Usage{}, &StatusError{Code: resp.StatusCode}
 	}
 
 	b, err := io.ReadAll(resp.Body)
@@ -121,14 +225,45 @@ func UseBookmark(base string, q url.Values) (Usage, error) {
 		return Usage{}, err
 	}
 
-	var usage Usage
-	if err := json.Unmarshal(b, &usage); err != nil {
-		return Usage{}, fmt.Errorf("failed to decode response: %w", err)
+	// Any 2xx means the use was counted; servers answering 204 or an empty or
+	// unexpected body only leave the counts unknown.
+	usage := Usage{Hash: reqBody.Hash, UserId: reqBody.UserId}
+	if len(bytes.TrimSpace(b)) > 0 {
+		_ = json.Unmarshal(b, &usage)
 	}
 
 	return usage, nil
 }
 
+// ErrBatchUnsupported is returned by UseBookmarks when the server has no batch endpoint.
+var ErrBatchUnsupported = errors.New("usage batches not supported by the server")
+
+// UseBookmarks reports several usage events at once via POST /api/usage/batch.
+func UseBookmarks(base string, reqs []UsageRequest) error {
+	body, err := json.Marshal(reqs)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest("POST", base+"/api/usage/batch", bytes.NewBuffer(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	resp, err := do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	switch {
+	case resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusMethodNotAllowed || resp.StatusCode == http.StatusNotImplemented:
+		return ErrBatchUnsupported
+	case resp.StatusCode >= 400:
+		return &StatusError{Code: resp.StatusCode}
+	}
+	return nil
+}
+
 func FetchBookmarks(base string, q url.Values) ([]Bookmark, error) {
 	endpoint := base + "/api/bookmarks"
 	if qs := q.Encode(); qs != "" {
@@ -139,13 +274,13 @@ func FetchBookmarks(base string, q url.Values) ([]Bookmark, error) {
 		return nil, err
 	}
 	req.Header.Set("Accept", "application/json")
-	resp, err := httpClient.Do(req)
+	resp, err := do(req)
 	if err != nil {
 		return nil, err
 	}
 	defer resp.Body.Close()
 	if resp.StatusCode >= 400 {
-		return nil, fmt.Errorf("http %d", resp.StatusCode)
+		return nil, &StatusError{Code: resp.StatusCode}
 	}
 	b, err := io.ReadAll(resp.Body)
 	if err != nil {
@@ -180,13 +315,13 @@ func SearchAI(base, query string) ([]Bookmark, error) {
 		return nil, err
 	}
 	req.Header.Set("Accept", "application/json")
-	resp, err := httpClient.Do(req)
+	resp, err := do(req)
 	if err != nil {
 		return nil, err
 	}
 	defer resp.Body.Close()
 	if resp.StatusCode >= 400 {
-		return nil, fmt.Errorf("http %d", resp.StatusCode)
+		return nil, &StatusError{Code: resp.StatusCode}
 	}
 	b, err := io.ReadAll(resp.Body)
 	if err != nil {
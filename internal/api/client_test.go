@@ -0,0 +1,102 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+func TestRetryable(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"nil", nil, false},
+		{"server error", &StatusError{Code: 502}, true},
+		{"too many requests", &StatusError{Code: 429}, true},
+		{"request timeout", &StatusError{Code: 408}, true},
+		{"wrapped", fmt.Errorf("usage: %w", &StatusError{Code: 503}), true},
+		{"bad request", &StatusError{Code: 400}, false},
+		{"not found", &StatusError{Code: 404}, false},
+		{"transport", &url.Error{Op: "Post", URL: "http://127.0.0.1:9", Err: errors.New("connection refused")}, true},
+		{"decode", errors.New("failed to decode response"), false},
+	}
+	for _, tt := range tests {
+		if got := Retryable(tt.err); got != tt.want {
+			t.Errorf("%s: Retryable(%v) = %v, want %v", tt.name, tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestUseBookmark(t *testing.T) {
+	tests := []struct {
+		name      string
+		status    int
+		body      string
+		usage     int
+		retryable bool
+		err       bool
+	}{
+		{name: "counts", status: 200, body: `{"hash":"abc","usage":3,"total_usage":9}`, usage: 3},
+		{name: "no content", status: 204},
+		{name: "empty body", status: 200},
+		{name: "not JSON", status: 200, body: "OK"},
+		{name: "created", status: 201, body: "\n"},
+		{name: "rejected", status: 400, err: true},
+		{name: "server error", status: 500, err: true, retryable: true},
+	}
+	q := url.Values{"hash": {"abc"}, "user_id": {"me"}}
+	for _, tt := range tests {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(tt.status)
+			fmt.Fprint(w, tt.body)
+		}))
+		u, err := UseBookmark(srv.URL, q)
+		srv.Close()
+		if (err != nil) != tt.err || Retryable(err) != tt.retryable {
+			t.Errorf("%s: UseBookmark error = %v (retryable %v)", tt.name, err, Retryable(err))
+			continue
+		}
+		if err == nil && (u.Usage != tt.usage || u.Hash != "abc") {
+			t.Errorf("%s: UseBookmark = %+v", tt.name, u)
+		}
+	}
+
+	// nothing listening: the event should be queued
+	srv := httptest.NewServer(http.NotFoundHandler())
+	srv.Close()
+	if _, err := UseBookmark(srv.URL, q); !Retryable(err) {
+		t.Errorf("UseBookmark to a closed server: error %v is not retryable", err)
+	}
+}
+
+func TestUseBookmarks(t *testing.T) {
+	tests := []struct {
+		status int
+		want   error
+	}{
+		{200, nil},
+		{204, nil},
+		{404, ErrBatchUnsupported},
+		{405, ErrBatchUnsupported},
+		{501, ErrBatchUnsupported},
+		{503, &StatusError{Code: 503}},
+	}
+	for _, tt := range tests {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/api/usage/batch" {
+				t.Errorf("request to %s", r.URL.Path)
+			}
+			w.WriteHeader(tt.status)
+		}))
+		err := UseBookmarks(srv.URL, []UsageRequest{{Hash: "abc"}, {Hash: "def"}})
+		srv.Close()
+		if fmt.Sprint(err) != fmt.Sprint(tt.want) {
+			t.Errorf("status %d: UseBookmarks = %v, want %v", tt.status, err, tt.want)
+		}
+	}
+}
@@ -19,23 +19,35 @@ type entry struct {
 	TS    int64          `json:"ts"`
 }
 
-func New() *diskCache {
-	return &diskCache{Path: filepath.Join(userCacheDir(), "bookmarks.json"), ttl: 5 * time.Minute}
+// New returns the bookmark list cache; entries older than ttl are treated as missing.
+func New(ttl time.Duration) *diskCache {
+	return &diskCache{Path: filepath.Join(userCacheDir(), "bookmarks.json"), ttl: ttl}
 }
 
 func (c *diskCache) Read() ([]api.Bookmark, bool) {
+	e, ok := c.load()
+	if !ok || time.Since(time.Unix(e.TS, 0)) > c.ttl {
+		return nil, false
+	}
+	return e.Items, true
+}
+
+// ReadStale returns the cached items regardless of their age.
+func (c *diskCache) ReadStale() ([]api.Bookmark, bool) {
+	e, ok := c.load()
+	return e.Items, ok
+}
+
+func (c *diskCache) load() (entry, bool) {
 	b, err := os.ReadFile(c.Path)
 	if err != nil {
-		return nil, false
+		return entry{}, false
 	}
 	var e entry
 	if json.Unmarshal(b, &e) != nil {
-		return nil, false
+		return entry{}, false
 	}
-	if time.Since(time.Unix(e.TS, 0)) > c.ttl {
-		return nil, false
-	}
-	return e.Items, true
+	return e, true
 }
 
 func (c *diskCache) Write(items []api.Bookmark) {
@@ -0,0 +1,47 @@
+package cache
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/amaterasu/markdex-cli/internal/api"
+)
+
+// Listing is the ordered result of the last list, search or pick, kept so that
+// markdex open <n> can refer to entries by their position.
+type Listing struct {
+	Profile string         `json:"profile"` // API base and user the listing was made for
+	Items   []api.Bookmark `json:"items"`
+	TS      int64          `json:"ts"`
+}
+
+// Age returns how long ago the listing was written.
+func (l Listing) Age() time.Duration { return time.Since(time.Unix(l.TS, 0)) }
+
+func listingPath() string { return filepath.Join(userCacheDir(), "last-listing.json") }
+
+// WriteListing replaces the stored listing.
+func WriteListing(profile string, items []api.Bookmark) {
+	if items == nil {
+		items = []api.Bookmark{}
+	}
+	p := listingPath()
+	_ = os.MkdirAll(filepath.Dir(p), 0o755)
+	b, _ := json.Marshal(Listing{Profile: profile, Items: items, TS: time.Now().Unix()})
+	_ = os.WriteFile(p, b, 0o644)
+}
+
+// ReadListing returns the stored listing, if any.
+func ReadListing() (Listing, bool) {
+	b, err := os.ReadFile(listingPath())
+	if err != nil {
+		return Listing{}, false
+	}
+	var l Listing
+	if json.Unmarshal(b, &l) != nil {
+		return Listing{}, false
+	}
+	return l, true
+}
@@ -0,0 +1,178 @@
+// Package clipboard copies text to and reads it from the system clipboard using whichever
+// tool the platform provides, or the OSC 52 terminal escape sequence (write only) when none
+// does, e.g. over SSH.
+package clipboard
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// Names lists the values accepted for the clipboard config key; "auto" picks the first
+// available tool in the order of the others.
+var Names = []string{"auto", "wayland", "xclip", "xsel", "pbcopy", "windows", "osc52"}
+
+type tool struct {
+	name  string
+	copy  []string // command reading the text on stdin
+	paste []string // command writing the clipboard to stdout; nil if unsupported
+	avail func() bool
+}
+
+var tools = []tool{
+	{name: "wayland", copy: []string{"wl-copy"}, paste: []string{"wl-paste", "--no-newline"},
+		avail: func() bool { return os.Getenv("WAYLAND_DISPLAY") != "" && found("wl-copy") }},
+	{name: "xclip", copy: []string{"xclip", "-selection", "clipboard"}, paste: []string{"xclip", "-selection", "clipboard", "-o"},
+		avail: func() bool { return os.Getenv("DISPLAY") != "" && found("xclip") }},
+	{name: "xsel", copy: []string{"xsel", "--clipboard", "--input"}, paste: []string{"xsel", "--clipboard", "--output"},
+		avail: func() bool { return os.Getenv("DISPLAY") != "" && found("xsel") }},
+	{name: "pbcopy", copy: []string{"pbcopy"}, paste: []string{"pbpaste"},
+		avail: func() bool { return runtime.GOOS == "darwin" && found("pbcopy") }},
+	// PowerShell reads stdin as UTF-8 text (clip.exe, the fallback, garbles non-ASCII); from
+	// WSL the Windows executables are reachable with their .exe names.
+	{name: "windows",
+		copy:  []string{"powershell.exe", "-NoProfile", "-Command", "[Console]::InputEncoding = [Text.Encoding]::UTF8; Set-Clipboard -Value ([Console]::In.ReadToEnd())"},
+		paste: []string{"powershell.exe", "-NoProfile", "-Command", "[Console]::OutputEncoding = [Text.Encoding]::UTF8; Get-Clipboard -Raw"},
+		avail: func() bool {
+			return (runtime.GOOS == "windows" || wsl()) && (found("powershell.exe") || found("clip.exe"))
+		}},
+	{name: "osc52", avail: func() bool {
+		tty := terminal()
+		if tty != nil {
+			tty.Close()
+		}
+		return tty != nil
+	}},
+}
+
+// Write puts text on the clipboard using the named tool ("" or "auto" to detect one).
+func Write(name, text string) error {
+	t, err := pick(name)
+	if err != nil {
+		return err
+	}
+	if t.name == "osc52" {
+		tty := terminal()
+		if tty == nil {
+			return errors.New("clipboard: osc52 needs a terminal")
+		}
+		defer tty.Close()
+		return WriteOSC52(tty, text)
+	}
+	cmd := t.copy
+	if t.name == "windows" && !found(cmd[0]) {
+		cmd = []string{"clip.exe"}
+	}
+	// xclip and wl-copy leave a process behind to serve the selection. It inherits the
+	// command's output, so that must not be a pipe: waiting for the pipe to close would
+	// wait for that process. Errors go to a file instead, read once the command exits.
+	errs, err := os.CreateTemp("", "markdex-clipboard-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(errs.Name())
+	defer errs.Close()
+	c := exec.Command(cmd[0], cmd[1:]...)
+	c.Stdin = strings.NewReader(text)
+	c.Stderr = errs
+	if err := c.Run(); err != nil {
+		out, _ := os.ReadFile(errs.Name())
+		return toolError(cmd[0], err, out)
+	}
+	return nil
+}
+
+// Read returns the clipboard contents using the named tool ("" or "auto" to detect one).
+func Read(name string) (string, error) {
+	t, err := pick(name)
+	if err != nil {
+		return "", err
+	}
+	if t.paste == nil {
+		if name == "" || name == "auto" {
+			return "", errors.New("clipboard: no tool to read the clipboard found (install wl-clipboard, xclip or xsel)")
+		}
+		return "", fmt.Errorf("clipboard: %s cannot read the clipboard", t.name)
+	}
+	var stderr bytes.Buffer
+	c := exec.Command(t.paste[0], t.paste[1:]...)
+	c.Stderr = &stderr
+	out, err := c.Output()
+	if err != nil {
+		return "", toolError(t.paste[0], err, stderr.Bytes())
+	}
+	s := string(out)
+	if t.name == "windows" {
+		s = strings.TrimSuffix(strings.ReplaceAll(s, "\r\n", "\n"), "\n")
+	}
+	return s, nil
+}
+
+// toolError describes a failed clipboard command, including what it printed.
+func toolError(cmd string, err error, output []byte) error {
+	if msg := strings.TrimSpace(string(output)); msg != "" {
+		return fmt.Errorf("clipboard: %s: %w: %s", cmd, err, msg)
+	}
+	return fmt.Errorf("clipboard: %s: %w", cmd, err)
+}
+
+// pick resolves a tool name; auto takes the first available one.
+func pick(name string) (tool, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	for _, t := range tools {
+		if name == t.name {
+			return t, nil
+		}
+	}
+	if name != "" && name != "auto" {
+		return tool{}, fmt.Errorf("clipboard: unknown tool %q (valid: %s)", name, strings.Join(Names, ", "))
+	}
+	for _, t := range tools {
+		if t.avail() {
+			return t, nil
+		}
+	}
+	return tool{}, errors.New("clipboard: no clipboard tool found (install wl-clipboard, xclip or xsel, or run in a terminal for OSC 52)")
+}
+
+// WriteOSC52 asks the terminal to put text on its clipboard using the OSC 52 escape sequence,
+// which reaches the local machine's clipboard through SSH. Inside tmux the sequence is wrapped
+// in a passthrough (tmux needs "set -g allow-passthrough on" or "set -g set-clipboard on").
+func WriteOSC52(w io.Writer, text string) error {
+	seq := "\x1b]52;c;" + base64.StdEncoding.EncodeToString([]byte(text)) + "\x07"
+	if os.Getenv("TMUX") != "" {
+		seq = "\x1bPtmux;\x1b" + seq + "\x1b\\"
+	}
+	_, err := fmt.Fprint(w, seq)
+	return err
+}
+
+// terminal opens the controlling terminal for writing, or returns nil without one.
+func terminal() *os.File {
+	f, err := os.OpenFile("/dev/tty", os.O_WRONLY, 0)
+	if err != nil {
+		return nil
+	}
+	return f
+}
+
+func found(cmd string) bool {
+	_, err := exec.LookPath(cmd)
+	return err == nil
+}
+
+// wsl reports whether we run under the Windows Subsystem for Linux.
+func wsl() bool {
+	if os.Getenv("WSL_DISTRO_NAME") != "" {
+		return true
+	}
+	b, err := os.ReadFile("/proc/version")
+	return err == nil && bytes.Contains(bytes.ToLower(b), []byte("microsoft"))
+}
@@ -0,0 +1,103 @@
+package clipboard
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeTool installs a shell script named name as the only command on PATH.
+func fakeTool(t *testing.T, name, script string) string {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("needs a POSIX shell")
+	}
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, name), []byte("#!/bin/sh\n"+script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+"/bin:/usr/bin")
+	return dir
+}
+
+func TestWrite(t *testing.T) {
+	tests := []struct {
+		name   string
+		script string // $OUT is where the fake tool stores its input
+		err    string
+	}{
+		{name: "exits", script: `cat > "$OUT"`},
+		// like xclip: a child keeps stdout and stderr open to serve the selection
+		{name: "forks", script: `cat > "$OUT"; (sleep 5) & exit 0`},
+		{name: "forks and writes", script: `cat > "$OUT"; echo serving; (sleep 5 >&2) &`},
+		{name: "fails", script: `cat > "$OUT"; echo "Error: Can't open display" >&2; exit 1`,
+			err: "clipboard: xclip: exit status 1: Error: Can't open display"},
+	}
+	for _, tt := range tests {
+		dir := fakeTool(t, "xclip", tt.script)
+		out := filepath.Join(dir, "out")
+		t.Setenv("OUT", out)
+		start := time.Now()
+		err := Write("xclip", "https://example.com/?a=1&b=2")
+		if d := time.Since(start); d > 3*time.Second {
+			t.Errorf("%s: Write took %v, waiting for the tool's child", tt.name, d)
+		}
+		if tt.err != "" {
+			if err == nil || err.Error() != tt.err {
+				t.Errorf("%s: Write error = %v, want %q", tt.name, err, tt.err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: Write: %v", tt.name, err)
+			continue
+		}
+		if b, _ := os.ReadFile(out); string(b) != "https://example.com/?a=1&b=2" {
+			t.Errorf("%s: tool got %q", tt.name, b)
+		}
+	}
+}
+
+func TestRead(t *testing.T) {
+	fakeTool(t, "xclip", `printf 'https://example.com/x'`)
+	got, err := Read("xclip")
+	if err != nil || got != "https://example.com/x" {
+		t.Errorf("Read = %q, %v", got, err)
+	}
+	if _, err := Read("osc52"); err == nil || err.Error() != "clipboard: osc52 cannot read the clipboard" {
+		t.Errorf("Read(osc52) error = %v", err)
+	}
+}
+
+func TestPick(t *testing.T) {
+	t.Setenv("PATH", t.TempDir())
+	for _, name := range Names[1:] {
+		if tl, err := pick(strings.ToUpper(name)); err != nil || tl.name != name {
+			t.Errorf("pick(%q) = %q, %v", name, tl.name, err)
+		}
+	}
+	if _, err := pick("clippy"); err == nil || !strings.Contains(err.Error(), `unknown tool "clippy"`) {
+		t.Errorf("pick(clippy) error = %v", err)
+	}
+}
+
+func TestWriteOSC52(t *testing.T) {
+	tests := []struct {
+		tmux string
+		want string
+	}{
+		{"", "\x1b]52;c;aGk=\x07"},
+		{"/tmp/tmux-1000/default,1,0", "\x1bPtmux;\x1b\x1b]52;c;aGk=\x07\x1b\\"},
+	}
+	for _, tt := range tests {
+		t.Setenv("TMUX", tt.tmux)
+		var b bytes.Buffer
+		if err := WriteOSC52(&b, "hi"); err != nil || b.String() != tt.want {
+			t.Errorf("TMUX=%q: WriteOSC52 = %q, %v; want %q", tt.tmux, b.String(), err, tt.want)
+		}
+	}
+}
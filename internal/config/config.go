@@ -1,16 +1,37 @@
 package config
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
+	"time"
 
 	"github.com/spf13/viper"
+
+	"github.com/amaterasu/markdex-cli/internal/urlnorm"
 )
 
-// Config represents persisted configuration values.
+// Config represents persisted configuration values. Scalar fields are declared in Schema.
 type Config struct {
-	APIBase string `toml:"apiBase"`
-	UserID  string `toml:"userId"`
+	APIBase          string        `toml:"apiBase"`
+	UserID           string        `toml:"userId"`
+	CacheTTL         time.Duration `toml:"cacheTTL"`
+	DefaultSort      string        `toml:"defaultSort"`
+	Browser          string        `toml:"browser"`
+	RemoteOpen       string        `toml:"remoteOpen"`
+	RemoteCommand    string        `toml:"remoteCommand"`
+	Clipboard        string        `toml:"clipboard"`
+	FzfOptions       string        `toml:"fzfOptions"`
+	ServerTagFilters bool          `toml:"serverTagFilters"`
+	Timeout          time.Duration `toml:"timeout"`
+	URLRules         string        `toml:"urlRules"`
+	TrackingParams   string        `toml:"trackingParams"`
+
+	Templates map[string]string      `toml:"templates"`
+	PickKeys  map[string]string      `toml:"pickKeys"`
+	Saved     map[string]SavedSearch `toml:"saved"`
+	OpenRules []OpenRule             `toml:"openRules"`
 }
 
 func configDir() string {
@@ -24,39 +45,180 @@ func configDir() string {
 
 func configPath() string { return filepath.Join(configDir(), "config.toml") }
 
+// URLNormalizer returns the configured URL normalization rules.
+func (c *Config) URLNormalizer() urlnorm.Rules {
+	r, err := urlnorm.ParseRules(c.URLRules, c.TrackingParams)
+	if err != nil {
+		r, _ = urlnorm.ParseRules(urlnorm.DefaultRules, c.TrackingParams)
+	}
+	return r
+}
+
 // Path returns the absolute path to the configuration file (may be relative if home directory lookup failed).
 func Path() string { return configPath() }
 
-// Load reads configuration from config.toml. It returns an empty Config and the error
+// Defaults returns a Config populated with the schema defaults.
+func Defaults() *Config {
+	c := &Config{Saved: map[string]SavedSearch{}}
+	for _, t := range Tables {
+		*t.field(c) = map[string]string{}
+	}
+	for _, k := range Schema {
+		if v, err := k.Parse(k.Default); err == nil {
+			k.assign(c, v)
+		}
+	}
+	return c
+}
+
+// Load reads configuration from config.toml. It returns a Config with defaults and the error
 // if the file cannot be read (callers commonly ignore the error to allow empty defaults).
+// Invalid values are replaced by their defaults and reported through the returned error.
 func Load() (*Config, error) {
-	vp := viper.New()
-	vp.SetConfigFile(configPath())
-	vp.SetConfigType("toml")
-	if err := vp.ReadInConfig(); err != nil {
+	vp, err := read(configPath())
+	if err != nil {
 		// On error (e.g., file missing), still return a config with sensible defaults.
-		return &Config{UserID: "default"}, err
+		return Defaults(), err
 	}
-	c := &Config{
-		APIBase: vp.GetString("apiBase"),
-		UserID:  vp.GetString("userId"),
+	return fromViper(vp)
+}
+
+// ValidateFile parses the config file at path and checks every known key against Schema.
+func ValidateFile(path string) error {
+	vp, err := read(path)
+	if err != nil {
+		return err
 	}
-	if c.UserID == "" {
-		c.UserID = "default"
+	_, err = fromViper(vp)
+	return err
+}
+
+// Get returns the value of key in c; key is either a schema key or "<table>.<name>".
+func Get(c *Config, key string) (string, error) {
+	if table, name, ok := strings.Cut(key, "."); ok {
+		t, ok := LookupTable(table)
+		if !ok {
+			return "", fmt.Errorf("unknown config table %q", table)
+		}
+		v, ok := t.Entries(c)[strings.ToLower(name)]
+		if !ok {
+			return "", fmt.Errorf("%s.%s is not set", t.Name, name)
+		}
+		return v, nil
 	}
-	return c, nil
+	k, ok := Lookup(key)
+	if !ok {
+		return "", fmt.Errorf("unknown config key %q", key)
+	}
+	return k.Value(c), nil
 }
 
-// Save persists the configuration to config.toml using TOML format via Viper.
-func Save(c *Config) error {
-	if err := os.MkdirAll(configDir(), 0o755); err != nil {
-		return err
+// Set validates value against the schema for key and writes it to config.toml as given,
+// leaving the rest of the file (other keys, comments) as it is. Keys of the form "<table>.<name>" set a table entry.
+func Set(key, value string) error {
+	if table, name, ok := strings.Cut(key, "."); ok {
+		return setEntry(table, name, value)
 	}
+	k, ok := Lookup(key)
+	if !ok {
+		return fmt.Errorf("unknown config key %q", key)
+	}
+	v, err := k.Parse(value)
+	if err != nil {
+		return fmt.Errorf("%s: %w", k.Name, err)
+	}
+	// Numbers and booleans are written as such; anything else as the string given
+	// (TOML has no duration type, so 10m stays 10m).
+	lit := tomlString(strings.TrimSpace(value))
+	switch v.(type) {
+	case int, bool:
+		lit = fmt.Sprint(v)
+	}
+	return edit(func(f *tomlFile) { f.set([]string{k.Name}, lit) })
+}
+
+// Unset removes key from config.toml so its default applies again.
+func Unset(key string) error {
+	if table, name, ok := strings.Cut(key, "."); ok {
+		return setEntry(table, name, "")
+	}
+	k, ok := Lookup(key)
+	if !ok {
+		return fmt.Errorf("unknown config key %q", key)
+	}
+	return edit(func(f *tomlFile) { f.unset([]string{k.Name}) })
+}
+
+// setEntry writes (or, for an empty value, removes) a named entry of a config table.
+func setEntry(table, name, value string) error {
+	t, ok := LookupTable(table)
+	if !ok {
+		return fmt.Errorf("unknown config table %q", table)
+	}
+	name = strings.ToLower(name)
+	if name == "" || strings.ContainsAny(name, ". ") {
+		return fmt.Errorf("invalid %s name %q", t.Name, name)
+	}
+	if value != "" && t.Validate != nil {
+		if err := t.Validate(value); err != nil {
+			return fmt.Errorf("%s.%s: %w", t.Name, name, err)
+		}
+	}
+	return edit(func(f *tomlFile) {
+		if value == "" {
+			f.unset([]string{t.Name, name})
+		} else {
+			f.set([]string{t.Name, name}, tomlString(value))
+		}
+	})
+}
+
+func read(path string) (*viper.Viper, error) {
 	vp := viper.New()
-	vp.Set("apiBase", c.APIBase)
-	vp.Set("userId", c.UserID)
-	vp.SetConfigFile(configPath())
+	vp.SetConfigFile(path)
 	vp.SetConfigType("toml")
-	// WriteConfig will create or truncate the file.
-	return vp.WriteConfig()
+	if err := vp.ReadInConfig(); err != nil {
+		return vp, err
+	}
+	return vp, nil
+}
+
+func fromViper(vp *viper.Viper) (*Config, error) {
+	c := Defaults()
+	var firstErr error
+	for _, k := range Schema {
+		if !vp.IsSet(k.Name) {
+			continue
+		}
+		v, err := k.Parse(vp.GetString(k.Name))
+		if err != nil {
+			if firstErr == nil {
+				firstErr = fmt.Errorf("%s: %w", k.Name, err)
+			}
+			continue
+		}
+		k.assign(c, v)
+	}
+	for _, t := range Tables {
+		entries := map[string]string{}
+		for name, v := range vp.GetStringMapString(t.Name) {
+			if t.Validate != nil {
+				if err := t.Validate(v); err != nil {
+					if firstErr == nil {
+						firstErr = fmt.Errorf("%s.%s: %w", t.Name, name, err)
+					}
+					continue
+				}
+			}
+			entries[name] = v
+		}
+		*t.field(c) = entries
+	}
+	c.Saved = loadSaved(vp)
+	rules, err := loadRules(vp)
+	if err != nil && firstErr == nil {
+		firstErr = err
+	}
+	c.OpenRules = rules
+	return c, firstErr
 }
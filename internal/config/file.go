@@ -0,0 +1,447 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// tomlFile is config.toml as lines. Changes made by config set, unset and saved replace,
+// add or remove only the lines of the keys they touch, so that comments, the order of
+// keys and values as the user wrote them survive.
+type tomlFile struct {
+	lines    []string
+	entries  []entry
+	sections []section // sections[0] is the root, before any [table] header
+}
+
+// entry is a key = value in the file.
+type entry struct {
+	path       []string // full dotted path, including the table header
+	section    int      // index into sections
+	start, end int      // lines[start:end] hold the entry
+	valueEnd   int      // column after the value on its last line
+}
+
+// section is a [table] or [[array]] header and the lines up to the next one.
+type section struct {
+	path       []string
+	array      bool
+	start, end int // header line (-1 for the root) and the end of its body
+}
+
+func parseTOMLFile(text string) *tomlFile {
+	f := &tomlFile{}
+	if text = strings.TrimRight(text, "\n"); text != "" {
+		f.lines = strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+	}
+	f.scan()
+	return f
+}
+
+func (f *tomlFile) String() string {
+	s := strings.TrimRight(strings.Join(f.lines, "\n"), "\n")
+	if s == "" {
+		return ""
+	}
+	return s + "\n"
+}
+
+// scan finds the sections and entries of the current lines.
+func (f *tomlFile) scan() {
+	f.entries = nil
+	f.sections = []section{{start: -1, end: len(f.lines)}}
+	for i := 0; i < len(f.lines); i++ {
+		line := f.lines[i]
+		t := strings.TrimSpace(line)
+		if t == "" || t[0] == '#' {
+			continue
+		}
+		if t[0] == '[' {
+			array := strings.HasPrefix(t, "[[")
+			path, _, ok := parseKey(strings.TrimPrefix(t[1:], "["))
+			if !ok {
+				continue
+			}
+			f.sections[len(f.sections)-1].end = i
+			f.sections = append(f.sections, section{path: path, array: array, start: i, end: len(f.lines)})
+			continue
+		}
+		parts, n, ok := parseKey(line)
+		if !ok || n >= len(line) || line[n] != '=' {
+			continue
+		}
+		s := len(f.sections) - 1
+		endLine, endCol := valueEnd(f.lines, i, n+1)
+		path := append(append([]string(nil), f.sections[s].path...), parts...)
+		f.entries = append(f.entries, entry{path: path, section: s, start: i, end: endLine + 1, valueEnd: endCol})
+		i = endLine
+	}
+}
+
+// find returns the index of the entry at path (compared case-insensitively, as viper
+// reads keys), or -1.
+func (f *tomlFile) find(path []string) int {
+	for i, e := range f.entries {
+		if !f.sections[e.section].array && samePath(e.path, path) {
+			return i
+		}
+	}
+	return -1
+}
+
+// set writes path = value (a TOML literal), in place of the current entry if there is one
+// (keeping its trailing comment), else at the end of the path's table.
+func (f *tomlFile) set(path []string, value string) {
+	if i := f.find(path); i >= 0 {
+		e := f.entries[i]
+		first, last := f.lines[e.start], f.lines[e.end-1]
+		indent := first[:len(first)-len(strings.TrimLeft(first, " \t"))]
+		comment := ""
+		if rest := strings.TrimSpace(last[e.valueEnd:]); strings.HasPrefix(rest, "#") {
+			comment = " " + rest
+		}
+		rel := path[len(f.sections[e.section].path):]
+		f.splice(e.start, e.end, indent+formatKey(rel)+" = "+value+comment)
+		return
+	}
+	table := path[:len(path)-1]
+	for s, sec := range f.sections {
+		if sec.array || !samePath(sec.path, table) || (s == 0 && len(table) > 0) {
+			continue
+		}
+		line := formatKey(path[len(table):]) + " = " + value
+		at := -1
+		for _, e := range f.entries {
+			if e.section == s {
+				at = e.end
+			}
+		}
+		switch {
+		case at >= 0: // after the table's last key
+		case s > 0:
+			at = sec.start + 1
+		case sec.end == len(f.lines):
+			at = len(f.lines)
+		default:
+			// the first top-level key: before the first table and the comment right above it
+			at = sec.end
+			for at > 0 && strings.HasPrefix(strings.TrimSpace(f.lines[at-1]), "#") {
+				at--
+			}
+			f.splice(at, at, line, "")
+			return
+		}
+		f.splice(at, at, line)
+		return
+	}
+	// a table written as dotted keys (templates.short = "...") gets another one
+	at := -1
+	for _, e := range f.entries {
+		if e.section == 0 && len(e.path) > len(table) && samePath(e.path[:len(table)], table) {
+			at = e.end
+		}
+	}
+	if at >= 0 {
+		f.splice(at, at, formatKey(path)+" = "+value)
+		return
+	}
+	var add []string
+	if len(f.lines) > 0 && strings.TrimSpace(f.lines[len(f.lines)-1]) != "" {
+		add = append(add, "")
+	}
+	add = append(add, "["+formatKey(table)+"]", formatKey(path[len(table):])+" = "+value)
+	f.splice(len(f.lines), len(f.lines), add...)
+}
+
+// unset removes the entries at path and below it.
+func (f *tomlFile) unset(path []string) {
+	for i := len(f.entries) - 1; i >= 0; i-- {
+		e := f.entries[i]
+		if !f.sections[e.section].array && len(e.path) >= len(path) && samePath(e.path[:len(path)], path) {
+			f.splice(e.start, e.end)
+		}
+	}
+}
+
+// removeTables removes the [table] sections at path and below it, with their contents.
+func (f *tomlFile) removeTables(path []string) {
+	for s := len(f.sections) - 1; s > 0; s-- {
+		sec := f.sections[s]
+		if !sec.array && len(sec.path) >= len(path) && samePath(sec.path[:len(path)], path) {
+			end := sec.end
+			for end > sec.start+1 && strings.TrimSpace(f.lines[end-1]) == "" {
+				end-- // keep the blank lines before the next table
+			}
+			f.splice(sec.start, end)
+		}
+	}
+}
+
+// splice replaces lines[from:to] with repl and rescans.
+func (f *tomlFile) splice(from, to int, repl ...string) {
+	f.lines = append(f.lines[:from], append(repl, f.lines[to:]...)...)
+	f.scan()
+}
+
+func samePath(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !strings.EqualFold(a[i], b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+var bareKey = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// formatKey writes a dotted key, quoting the parts that need it.
+func formatKey(path []string) string {
+	parts := make([]string, len(path))
+	for i, p := range path {
+		if bareKey.MatchString(p) {
+			parts[i] = p
+		} else {
+			parts[i] = tomlString(p)
+		}
+	}
+	return strings.Join(parts, ".")
+}
+
+// tomlString writes s as a TOML string: a literal string when that avoids escaping quotes
+// or backslashes (as in templates), else a basic string.
+func tomlString(s string) string {
+	if strings.ContainsAny(s, `"\`) && !strings.ContainsAny(s, "'\n\r\t") && !hasControl(s) {
+		return "'" + s + "'"
+	}
+	var b strings.Builder
+	b.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"':
+			b.WriteString(`\"`)
+		case '\\':
+			b.WriteString(`\\`)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\r':
+			b.WriteString(`\r`)
+		case '\t':
+			b.WriteString(`\t`)
+		default:
+			if r < 0x20 || r == 0x7f {
+				fmt.Fprintf(&b, `\u%04X`, r)
+			} else {
+				b.WriteRune(r)
+			}
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
+
+func hasControl(s string) bool {
+	for _, r := range s {
+		if r < 0x20 || r == 0x7f {
+			return true
+		}
+	}
+	return false
+}
+
+// parseKey reads a dotted key at the start of s (after any indentation), returning its
+// parts and the index just after it and the spaces that follow.
+func parseKey(s string) (parts []string, n int, ok bool) {
+	i := 0
+	for {
+		for i < len(s) && (s[i] == ' ' || s[i] == '\t') {
+			i++
+		}
+		if i >= len(s) {
+			return nil, 0, false
+		}
+		switch s[i] {
+		case '"':
+			end := stringEnd(s, i)
+			p, err := strconv.Unquote(s[i:end])
+			if err != nil {
+				return nil, 0, false
+			}
+			parts, i = append(parts, p), end
+		case '\'':
+			end := stringEnd(s, i)
+			if end-i < 2 || s[end-1] != '\'' {
+				return nil, 0, false
+			}
+			parts, i = append(parts, s[i+1:end-1]), end
+		default:
+			j := i
+			for j < len(s) && (s[j] == '_' || s[j] == '-' || 'a' <= s[j] && s[j] <= 'z' || 'A' <= s[j] && s[j] <= 'Z' || '0' <= s[j] && s[j] <= '9') {
+				j++
+			}
+			if j == i {
+				return nil, 0, false
+			}
+			parts, i = append(parts, s[i:j]), j
+		}
+		for i < len(s) && (s[i] == ' ' || s[i] == '\t') {
+			i++
+		}
+		if i < len(s) && s[i] == '.' {
+			i++
+			continue
+		}
+		return parts, i, true
+	}
+}
+
+// valueEnd finds where the value starting at lines[line][col] ends: the line it ends on
+// and the column after it. Arrays, inline tables and multi-line strings may span lines.
+func valueEnd(lines []string, line, col int) (int, int) {
+	s := lines[line]
+	for col < len(s) && (s[col] == ' ' || s[col] == '\t') {
+		col++
+	}
+	rest := s[col:]
+	last := len(lines) - 1
+	switch {
+	case strings.HasPrefix(rest, `"""`), strings.HasPrefix(rest, "'''"):
+		delim := rest[:3]
+		for l, c := line, col+3; l < len(lines); l, c = l+1, 0 {
+			t := lines[l]
+			for ; c+3 <= len(t); c++ {
+				if delim == `"""` && t[c] == '\\' {
+					c++
+					continue
+				}
+				if t[c:c+3] == delim {
+					end := c + 3
+					for end < len(t) && end < c+5 && t[end] == delim[0] {
+						end++ // up to two more quotes close the string
+					}
+					return l, end
+				}
+			}
+		}
+		return last, len(lines[last])
+	case rest != "" && (rest[0] == '[' || rest[0] == '{'):
+		depth := 0
+		for l, c := line, col; l < len(lines); l, c = l+1, 0 {
+			t := lines[l]
+			for ; c < len(t); c++ {
+				switch t[c] {
+				case '[', '{':
+					depth++
+				case ']', '}':
+					if depth--; depth == 0 {
+						return l, c + 1
+					}
+				case '"', '\'':
+					c = stringEnd(t, c) - 1
+				case '#':
+					c = len(t)
+				}
+			}
+		}
+		return last, len(lines[last])
+	case rest != "" && (rest[0] == '"' || rest[0] == '\''):
+		return line, stringEnd(s, col)
+	}
+	if i := strings.IndexByte(rest, '#'); i >= 0 {
+		rest = rest[:i]
+	}
+	return line, col + len(strings.TrimRight(rest, " \t"))
+}
+
+// stringEnd returns the index just after the one-line string that starts at s[i].
+func stringEnd(s string, i int) int {
+	q := s[i]
+	for j := i + 1; j < len(s); j++ {
+		if q == '"' && s[j] == '\\' {
+			j++
+			continue
+		}
+		if s[j] == q {
+			return j + 1
+		}
+	}
+	return len(s)
+}
+
+// edit applies fn to config.toml and writes it back, refusing to write a file that no
+// longer parses.
+func edit(fn func(f *tomlFile)) error {
+	if err := os.MkdirAll(configDir(), 0o755); err != nil {
+		return err
+	}
+	b, err := os.ReadFile(configPath())
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if err := parses(b); err != nil {
+		return fmt.Errorf("cannot parse %s: %w", configPath(), err)
+	}
+	f := parseTOMLFile(string(b))
+	fn(f)
+	out := []byte(f.String())
+	if err := parses(out); err != nil {
+		return fmt.Errorf("cannot update %s: %w", configPath(), err)
+	}
+	return os.WriteFile(configPath(), out, 0o644)
+}
+
+func parses(b []byte) error {
+	vp := viper.New()
+	vp.SetConfigType("toml")
+	return vp.ReadConfig(bytes.NewReader(b))
+}
+
+// UnknownKeys returns the keys in the config file at path that markdex does not use
+// (e.g. misspelled ones), as written there.
+func UnknownKeys(path string) ([]string, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var out []string
+	for _, e := range parseTOMLFile(string(b)).entries {
+		if !knownKey(e.path) {
+			out = append(out, formatKey(e.path))
+		}
+	}
+	return out, nil
+}
+
+func knownKey(path []string) bool {
+	oneOf := func(s string, names ...string) bool {
+		for _, n := range names {
+			if strings.EqualFold(s, n) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(path) == 1 {
+		_, ok := Lookup(path[0])
+		return ok
+	}
+	if _, ok := LookupTable(path[0]); ok {
+		return len(path) == 2
+	}
+	switch {
+	case strings.EqualFold(path[0], "saved"):
+		// [saved.<name>] tables, or inline tables under [saved]
+		return len(path) == 2 || len(path) == 3 && oneOf(path[2], "query", "sort", "output")
+	case strings.EqualFold(path[0], "openRules"):
+		return len(path) == 2 && oneOf(path[1], "tag", "host", "url", "command")
+	}
+	return false
+}
@@ -0,0 +1,232 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const sampleConfig = `# markdex settings
+apiBase = "https://api.example.com"  # production
+defaultSort = "usage"
+cacheTTL = "10m"
+
+# output
+[templates]
+links = '{{.Title}}: {{.URL}}'
+
+[[openRules]]
+tag = "work"
+command = "firefox -P work {url}"
+
+[saved.work]
+query = "tag:work"
+sort = "usage"
+`
+
+// withConfig points the config path at a temporary home holding text.
+func withConfig(t *testing.T, text string) string {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+	if text != "" {
+		if err := os.MkdirAll(filepath.Dir(Path()), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(Path(), []byte(text), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return Path()
+}
+
+func TestEditConfig(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		do   func() error
+		want string // the whole file afterwards; "" means sampleConfig with replace applied
+		old  string
+		new  string
+	}{
+		{
+			name: "set keeps spelling, comments and other values",
+			in:   sampleConfig,
+			do:   func() error { return Set("cachettl", "20s") },
+			old:  `cacheTTL = "10m"`, new: `cacheTTL = "20s"`,
+		},
+		{
+			name: "set keeps the trailing comment",
+			in:   sampleConfig,
+			do:   func() error { return Set("apiBase", "https://staging.example.com/") },
+			old:  `apiBase = "https://api.example.com"  # production`, new: `apiBase = "https://staging.example.com/" # production`,
+		},
+		{
+			name: "set adds a new key after the others",
+			in:   sampleConfig,
+			do:   func() error { return Set("timeout", "30s") },
+			old:  `cacheTTL = "10m"`, new: "cacheTTL = \"10m\"\ntimeout = \"30s\"",
+		},
+		{
+			name: "booleans stay booleans",
+			in:   sampleConfig,
+			do:   func() error { return Set("serverTagFilters", "true") },
+			old:  `cacheTTL = "10m"`, new: "cacheTTL = \"10m\"\nserverTagFilters = true",
+		},
+		{
+			name: "unset removes only that line",
+			in:   sampleConfig,
+			do:   func() error { return Unset("defaultSort") },
+			old:  "defaultSort = \"usage\"\n", new: "",
+		},
+		{
+			name: "table entry",
+			in:   sampleConfig,
+			do:   func() error { return Set("templates.short", `{{short .Hash}} {{join "," .Tags}}`) },
+			old:  "links = '{{.Title}}: {{.URL}}'", new: "links = '{{.Title}}: {{.URL}}'\nshort = '{{short .Hash}} {{join \",\" .Tags}}'",
+		},
+		{
+			name: "new table",
+			in:   sampleConfig,
+			do:   func() error { return Set("pickKeys.edit", "alt-e") },
+			old:  "sort = \"usage\"\n", new: "sort = \"usage\"\n\n[pickKeys]\nedit = \"alt-e\"\n",
+		},
+		{
+			name: "unset table entry",
+			in:   sampleConfig,
+			do:   func() error { return Unset("templates.links") },
+			old:  "links = '{{.Title}}: {{.URL}}'\n", new: "",
+		},
+		{
+			name: "replace saved search",
+			in:   sampleConfig,
+			do:   func() error { return SaveSearch("work", SavedSearch{Query: "tag:work -tag:old", Output: "json"}) },
+			old:  "query = \"tag:work\"\nsort = \"usage\"\n", new: "query = \"tag:work -tag:old\"\noutput = \"json\"\n",
+		},
+		{
+			name: "remove saved search",
+			in:   sampleConfig,
+			do:   func() error { return RemoveSearch("work") },
+			old:  "\n[saved.work]\nquery = \"tag:work\"\nsort = \"usage\"\n", new: "",
+		},
+		{
+			name: "dotted table keys",
+			in:   "templates.a = \"{{.URL}}\"\n",
+			do:   func() error { return Set("templates.b", "{{.Title}}") },
+			want: "templates.a = \"{{.URL}}\"\ntemplates.b = \"{{.Title}}\"\n",
+		},
+		{
+			name: "first top-level key goes above the tables",
+			in:   "# mine\n\n# output\n[templates]\nlinks = \"{{.URL}}\"\n",
+			do:   func() error { return Set("apiBase", "https://api.example.com") },
+			want: "# mine\n\napiBase = \"https://api.example.com\"\n\n# output\n[templates]\nlinks = \"{{.URL}}\"\n",
+		},
+		{
+			name: "no file yet",
+			do:   func() error { return Set("userId", "me") },
+			want: "userId = \"me\"\n",
+		},
+		{
+			name: "multi-line values are replaced whole",
+			in:   "fzfOptions = \"\"\"\n--height 40%\n\"\"\" # tall\nbrowser = \"firefox\"\n",
+			do:   func() error { return Set("fzfOptions", "--height 50%") },
+			want: "fzfOptions = \"--height 50%\" # tall\nbrowser = \"firefox\"\n",
+		},
+	}
+	for _, tt := range tests {
+		path := withConfig(t, tt.in)
+		if err := tt.do(); err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		want := tt.want
+		if want == "" {
+			if !strings.Contains(sampleConfig, tt.old) {
+				t.Fatalf("%s: %q is not in the sample", tt.name, tt.old)
+			}
+			want = strings.Replace(sampleConfig, tt.old, tt.new, 1)
+		}
+		got, _ := os.ReadFile(path)
+		if string(got) != want {
+			t.Errorf("%s: file is\n%s\nwant\n%s", tt.name, got, want)
+		}
+		if _, err := Load(); err != nil {
+			t.Errorf("%s: the file does not load: %v", tt.name, err)
+		}
+	}
+}
+
+func TestSetValues(t *testing.T) {
+	withConfig(t, "")
+	if err := Set("cacheTTL", "90s"); err != nil {
+		t.Fatal(err)
+	}
+	if err := Set("urlRules", "tracking, https"); err != nil {
+		t.Fatal(err)
+	}
+	c, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := Get(c, "cacheTTL"); got != "1m30s" {
+		t.Errorf("cacheTTL = %q", got)
+	}
+	if got, _ := Get(c, "urlRules"); got != "tracking,https" {
+		t.Errorf("urlRules = %q", got)
+	}
+	if err := Set("timeout", "soon"); err == nil || !strings.Contains(err.Error(), "not a duration") {
+		t.Errorf("Set(timeout, soon) error = %v", err)
+	}
+}
+
+func TestUnknownKeys(t *testing.T) {
+	path := withConfig(t, `apibase = "https://api.example.com"
+apiBsae = "https://typo.example.com"
+templates.links = "{{.URL}}"
+
+[pickKeys]
+edit = "alt-e"
+[pickKeys.more]
+x = "y"
+
+[[openRules]]
+tag = "work"
+comand = "firefox"
+
+[saved.work]
+query = "tag:work"
+order = "usage"
+
+[saved]
+inline = { query = "x" }
+
+[colours]
+title = "red"
+`)
+	got, err := UnknownKeys(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "apiBsae pickKeys.more.x openRules.comand saved.work.order colours.title"
+	if strings.Join(got, " ") != want {
+		t.Errorf("UnknownKeys = %q, want %q", got, want)
+	}
+}
+
+func TestTOMLString(t *testing.T) {
+	tests := map[string]string{
+		"plain":              `"plain"`,
+		`{{join "," .Tags}}`: `'{{join "," .Tags}}'`,
+		`C:\Users`:           `'C:\Users'`,
+		`it's "x"`:           `"it's \"x\""`,
+		"tab\there":          `"tab\there"`,
+		"bell\a":             `"bell\u0007"`,
+	}
+	for in, want := range tests {
+		if got := tomlString(in); got != want {
+			t.Errorf("tomlString(%q) = %s, want %s", in, got, want)
+		}
+	}
+}
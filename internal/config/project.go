@@ -0,0 +1,81 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// ProjectFile is the name of the project-local configuration file.
+const ProjectFile = ".markdex.toml"
+
+// Project holds settings from a .markdex.toml found in the working directory or one of its parents.
+//
+//	tags = ["go", "markdex"]
+//	sourceFile = "projects/markdex.md"
+//
+//	[scope]
+//	tags = ["markdex"]
+//	sourceFiles = ["projects/markdex.md"]
+//	sections = ["Markdex"]
+type Project struct {
+	Path       string // absolute path of the .markdex.toml
+	Tags       []string
+	SourceFile string
+	Scope      Scope
+}
+
+// Scope restricts bookmarks to a project. A bookmark is in scope when it matches every
+// non-empty list: at least one of Tags, and one of SourceFiles and Sections.
+type Scope struct {
+	Tags        []string
+	SourceFiles []string
+	Sections    []string
+}
+
+// Empty reports whether the scope has no criteria.
+func (s Scope) Empty() bool {
+	return len(s.Tags) == 0 && len(s.SourceFiles) == 0 && len(s.Sections) == 0
+}
+
+// FindProject walks up from dir looking for a .markdex.toml. It returns nil (and no error)
+// when none is found.
+func FindProject(dir string) (*Project, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	for {
+		path := filepath.Join(dir, ProjectFile)
+		if st, err := os.Stat(path); err == nil && !st.IsDir() {
+			return loadProject(path)
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return nil, nil
+		}
+		dir = parent
+	}
+}
+
+func loadProject(path string) (*Project, error) {
+	vp, err := read(path)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	p := &Project{
+		Path:       path,
+		Tags:       vp.GetStringSlice("tags"),
+		SourceFile: vp.GetString("sourceFile"),
+		Scope: Scope{
+			Tags:        vp.GetStringSlice("scope.tags"),
+			SourceFiles: vp.GetStringSlice("scope.sourceFiles"),
+			Sections:    vp.GetStringSlice("scope.sections"),
+		},
+	}
+	// Without an explicit scope the project's default tags define it.
+	if p.Scope.Empty() {
+		p.Scope.Tags = p.Tags
+	}
+	return p, nil
+}
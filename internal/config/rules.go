@@ -0,0 +1,118 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// OpenRule picks the command that opens matching bookmarks. Rules are written as
+// [[openRules]] tables in config.toml and tried in order; the first match wins:
+//
+//	[[openRules]]
+//	tag = "work"
+//	command = "firefox -P work {url}"
+//
+//	[[openRules]]
+//	host = "github.com"
+//	command = "gh-open {url}"
+//
+// A rule matches when all of its non-empty conditions hold: Tag is one of the bookmark's
+// tags, Host is the URL host or a parent domain of it, and URL is a pattern for the whole
+// URL where '*' matches any run of characters.
+type OpenRule struct {
+	Tag     string `json:"tag,omitempty" yaml:"tag,omitempty"`
+	Host    string `json:"host,omitempty" yaml:"host,omitempty"`
+	URL     string `json:"url,omitempty" yaml:"url,omitempty"`
+	Command string `json:"command" yaml:"command"`
+}
+
+// Match reports whether the rule applies to a bookmark with the given tags and URL.
+func (r OpenRule) Match(tags []string, rawURL string) bool {
+	if r.Tag != "" && !containsFold(tags, r.Tag) {
+		return false
+	}
+	if r.Host != "" {
+		u, err := url.Parse(rawURL)
+		if err != nil {
+			return false
+		}
+		h := strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+		want := strings.TrimPrefix(strings.ToLower(r.Host), "www.")
+		if h != want && !strings.HasSuffix(h, "."+want) {
+			return false
+		}
+	}
+	if r.URL != "" && !globMatch(r.URL, rawURL) {
+		return false
+	}
+	return true
+}
+
+// BrowserFor returns the command of the first open rule matching the bookmark, or the
+// browser key when none does (empty means $BROWSER or the platform opener).
+func (c *Config) BrowserFor(tags []string, rawURL string) string {
+	for _, r := range c.OpenRules {
+		if r.Match(tags, rawURL) {
+			return r.Command
+		}
+	}
+	return c.Browser
+}
+
+func containsFold(xs []string, s string) bool {
+	for _, x := range xs {
+		if strings.EqualFold(x, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// globMatch matches s against pattern, where '*' matches any (possibly empty) run of characters.
+func globMatch(pattern, s string) bool {
+	parts := strings.Split(pattern, "*")
+	for i, p := range parts {
+		parts[i] = regexp.QuoteMeta(p)
+	}
+	re, err := regexp.Compile("(?i)^" + strings.Join(parts, ".*") + "$")
+	return err == nil && re.MatchString(s)
+}
+
+func loadRules(vp *viper.Viper) ([]OpenRule, error) {
+	raw, ok := vp.Get("openRules").([]any)
+	if !ok {
+		if vp.IsSet("openRules") {
+			return nil, fmt.Errorf("openRules: expected [[openRules]] tables")
+		}
+		return nil, nil
+	}
+	var rules []OpenRule
+	var firstErr error
+	for i, item := range raw {
+		m, ok := item.(map[string]any)
+		if !ok {
+			continue
+		}
+		str := func(k string) string { s, _ := m[k].(string); return strings.TrimSpace(s) }
+		r := OpenRule{Tag: str("tag"), Host: str("host"), URL: str("url"), Command: str("command")}
+		var err error
+		switch {
+		case r.Command == "":
+			err = fmt.Errorf("openRules[%d]: command is required", i)
+		case r.Tag == "" && r.Host == "" && r.URL == "":
+			err = fmt.Errorf("openRules[%d]: set at least one of tag, host or url", i)
+		}
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		rules = append(rules, r)
+	}
+	return rules, firstErr
+}
@@ -0,0 +1,111 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestOpenRuleMatch(t *testing.T) {
+	tests := []struct {
+		name string
+		rule OpenRule
+		tags []string
+		url  string
+		want bool
+	}{
+		{"tag", OpenRule{Tag: "work"}, []string{"go", "Work"}, "https://a.example/", true},
+		{"missing tag", OpenRule{Tag: "work"}, []string{"go"}, "https://a.example/", false},
+		{"host", OpenRule{Host: "github.com"}, nil, "https://github.com/a/b", true},
+		{"subdomain", OpenRule{Host: "github.com"}, nil, "https://gist.github.com/x", true},
+		{"www and case", OpenRule{Host: "www.GitHub.com"}, nil, "https://GITHUB.com:443/", true},
+		{"suffix is not a subdomain", OpenRule{Host: "hub.com"}, nil, "https://github.com/", false},
+		{"other host", OpenRule{Host: "github.com"}, nil, "https://gitlab.com/", false},
+		{"host of a bad URL", OpenRule{Host: "a.example"}, nil, "http://a.example/%zz", false},
+		{"url pattern", OpenRule{URL: "https://docs.*/go/*"}, nil, "https://docs.example.com/go/intro", true},
+		{"url pattern is anchored", OpenRule{URL: "https://docs.*/go"}, nil, "https://docs.example.com/go/intro", false},
+		{"all must hold", OpenRule{Tag: "work", Host: "github.com"}, []string{"work"}, "https://gitlab.com/", false},
+		{"all hold", OpenRule{Tag: "work", Host: "github.com", URL: "*/acme/*"}, []string{"work"}, "https://github.com/acme/x", true},
+	}
+	for _, tt := range tests {
+		if got := tt.rule.Match(tt.tags, tt.url); got != tt.want {
+			t.Errorf("%s: Match = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestGlobMatch(t *testing.T) {
+	tests := []struct {
+		pattern, s string
+		want       bool
+	}{
+		{"*", "", true},
+		{"*", "anything", true},
+		{"a*c", "abbbc", true},
+		{"a*c", "ac", true},
+		{"a*c", "acd", false},
+		{"*.pdf", "https://a.example/x.PDF", true},
+		{"*.pdf", "https://a.example/xpdf", false}, // '.' is literal
+		{"https://a.example/?q=(x)", "https://a.example/?q=(x)", true},
+		{"https://a.example/?q=(x)", "https://a.example/q=(x)", false},
+	}
+	for _, tt := range tests {
+		if got := globMatch(tt.pattern, tt.s); got != tt.want {
+			t.Errorf("globMatch(%q, %q) = %v, want %v", tt.pattern, tt.s, got, tt.want)
+		}
+	}
+}
+
+func TestBrowserFor(t *testing.T) {
+	withConfig(t, `browser = "firefox"
+
+[[openRules]]
+tag = "work"
+command = "firefox -P work {url}"
+
+[[openRules]]
+host = "github.com"
+command = "gh-open {url}"
+`)
+	c, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		tags []string
+		url  string
+		want string
+	}{
+		{[]string{"work"}, "https://github.com/x", "firefox -P work {url}"}, // the first match wins
+		{nil, "https://github.com/x", "gh-open {url}"},
+		{nil, "https://example.com/", "firefox"},
+	}
+	for _, tt := range tests {
+		if got := c.BrowserFor(tt.tags, tt.url); got != tt.want {
+			t.Errorf("BrowserFor(%q, %q) = %q, want %q", tt.tags, tt.url, got, tt.want)
+		}
+	}
+}
+
+func TestLoadRules(t *testing.T) {
+	tests := []struct {
+		text  string
+		rules int
+		err   string
+	}{
+		{"# no rules\n", 0, ""},
+		{"[[openRules]]\nurl = \"*.pdf\"\ncommand = \"zathura {url}\"\n", 1, ""},
+		{"[[openRules]]\ntag = \"work\"\n\n[[openRules]]\nhost = \"a.example\"\ncommand = \"x\"\n", 1, "openRules[0]: command is required"},
+		{"[[openRules]]\ncommand = \"x\"\n", 0, "openRules[0]: set at least one of tag, host or url"},
+		{"openRules = \"firefox\"\n", 0, "openRules: expected [[openRules]] tables"},
+	}
+	for _, tt := range tests {
+		withConfig(t, tt.text)
+		c, err := Load()
+		if tt.err == "" && err != nil || tt.err != "" && (err == nil || !strings.Contains(err.Error(), tt.err)) {
+			t.Errorf("%q: Load error = %v, want %q", tt.text, err, tt.err)
+		}
+		if len(c.OpenRules) != tt.rules {
+			t.Errorf("%q: %d rules, want %d", tt.text, len(c.OpenRules), tt.rules)
+		}
+	}
+}
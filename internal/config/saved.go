@@ -0,0 +1,67 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// SavedSearch is a named query stored under [saved.<name>] in config.toml, with an
+// optional default sort order and output format.
+type SavedSearch struct {
+	Query  string `json:"query" yaml:"query"`
+	Sort   string `json:"sort,omitempty" yaml:"sort,omitempty"`
+	Output string `json:"output,omitempty" yaml:"output,omitempty"`
+}
+
+var savedName = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// ValidSavedName reports whether name can be used for a saved search (lowercase letters,
+// digits, '-' and '_'; names are case-insensitive).
+func ValidSavedName(name string) bool { return savedName.MatchString(strings.ToLower(name)) }
+
+// SaveSearch stores (or replaces) a saved search.
+func SaveSearch(name string, s SavedSearch) error {
+	name = strings.ToLower(name)
+	if !ValidSavedName(name) {
+		return fmt.Errorf("invalid saved search name %q (use letters, digits, '-' and '_')", name)
+	}
+	return edit(func(f *tomlFile) {
+		f.unset([]string{"saved", name})
+		f.set([]string{"saved", name, "query"}, tomlString(s.Query))
+		if s.Sort != "" {
+			f.set([]string{"saved", name, "sort"}, tomlString(s.Sort))
+		}
+		if s.Output != "" {
+			f.set([]string{"saved", name, "output"}, tomlString(s.Output))
+		}
+	})
+}
+
+// RemoveSearch deletes a saved search; it fails if name does not exist.
+func RemoveSearch(name string) error {
+	name = strings.ToLower(name)
+	c, _ := Load()
+	if _, ok := c.Saved[name]; !ok {
+		return fmt.Errorf("no saved search named %q", name)
+	}
+	return edit(func(f *tomlFile) {
+		f.unset([]string{"saved", name})
+		f.removeTables([]string{"saved", name})
+	})
+}
+
+func loadSaved(vp *viper.Viper) map[string]SavedSearch {
+	out := map[string]SavedSearch{}
+	for name := range vp.GetStringMap("saved") {
+		key := "saved." + name
+		out[name] = SavedSearch{
+			Query:  vp.GetString(key + ".query"),
+			Sort:   vp.GetString(key + ".sort"),
+			Output: vp.GetString(key + ".output"),
+		}
+	}
+	return out
+}
@@ -0,0 +1,64 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidSavedName(t *testing.T) {
+	for name, want := range map[string]bool{
+		"work":    true,
+		"Work":    true,
+		"to-read": true,
+		"go_1":    true,
+		"1st":     true,
+		"":        false,
+		"-x":      false,
+		"my list": false,
+		"a.b":     false,
+	} {
+		if got := ValidSavedName(name); got != want {
+			t.Errorf("ValidSavedName(%q) = %v, want %v", name, got, want)
+		}
+	}
+}
+
+func TestSavedSearches(t *testing.T) {
+	withConfig(t, "")
+	if err := SaveSearch("Work", SavedSearch{Query: `tag:work "design doc"`, Sort: "usage"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := SaveSearch("later", SavedSearch{Query: "tag:later", Output: "json"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := SaveSearch("bad name", SavedSearch{Query: "x"}); err == nil {
+		t.Error("SaveSearch accepted an invalid name")
+	}
+	c, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]SavedSearch{
+		"work":  {Query: `tag:work "design doc"`, Sort: "usage"},
+		"later": {Query: "tag:later", Output: "json"},
+	}
+	if len(c.Saved) != len(want) {
+		t.Errorf("Saved = %+v, want %+v", c.Saved, want)
+	}
+	for name, s := range want {
+		if c.Saved[name] != s {
+			t.Errorf("Saved[%q] = %+v, want %+v", name, c.Saved[name], s)
+		}
+	}
+
+	if err := RemoveSearch("WORK"); err != nil {
+		t.Fatal(err)
+	}
+	if err := RemoveSearch("work"); err == nil || !strings.Contains(err.Error(), `no saved search named "work"`) {
+		t.Errorf("removing it again: error = %v", err)
+	}
+	c, _ = Load()
+	if _, ok := c.Saved["work"]; ok || len(c.Saved) != 1 {
+		t.Errorf("after RemoveSearch, Saved = %+v", c.Saved)
+	}
+}
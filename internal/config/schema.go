@@ -0,0 +1,252 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/amaterasu/markdex-cli/internal/urlnorm"
+)
+
+// Type describes how a configuration value is parsed and validated.
+type Type int
+
+const (
+	TypeString Type = iota
+	TypeURL
+	TypeDuration
+	TypeInt
+	TypeBool
+	TypeEnum
+	TypeList
+)
+
+func (t Type) String() string {
+	switch t {
+	case TypeURL:
+		return "url"
+	case TypeDuration:
+		return "duration"
+	case TypeInt:
+		return "int"
+	case TypeBool:
+		return "bool"
+	case TypeEnum:
+		return "enum"
+	case TypeList:
+		return "list"
+	default:
+		return "string"
+	}
+}
+
+// Key declares a single configuration key: its name as written in config.toml,
+// its type, default value and a one-line description.
+type Key struct {
+	Name        string
+	Type        Type
+	Default     string
+	Description string
+	Choices     []string // allowed values for TypeEnum, or for each item of a TypeList
+
+	// field returns a pointer to the Config field backing this key.
+	field func(c *Config) any
+}
+
+// Schema lists every scalar key understood by markdex, in display order.
+var Schema = []Key{
+	{Name: "apiBase", Type: TypeURL, Description: "Markdex API base URL (absolute http or https URL)",
+		field: func(c *Config) any { return &c.APIBase }},
+	{Name: "userId", Type: TypeString, Default: "default", Description: "User id reported with usage events (e.g., workspace or profile)",
+		field: func(c *Config) any { return &c.UserID }},
+	{Name: "cacheTTL", Type: TypeDuration, Default: "5m", Description: "How long the local bookmark cache stays fresh",
+		field: func(c *Config) any { return &c.CacheTTL }},
+	{Name: "defaultSort", Type: TypeEnum, Default: "title", Choices: []string{"title", "usage", "frecency", "url", "host", "source", "section"}, Description: "Default ordering for list and search",
+		field: func(c *Config) any { return &c.DefaultSort }},
+	{Name: "browser", Type: TypeString, Description: "Command used to open URLs, {url} marks where the URL goes (defaults to $BROWSER, then the platform opener)",
+		field: func(c *Config) any { return &c.Browser }},
+	{Name: "remoteOpen", Type: TypeEnum, Default: "print", Choices: []string{"print", "osc52", "command"}, Description: "What open does without a local display (e.g. over SSH): print the URL, copy it to the local clipboard via OSC 52, or run remoteCommand",
+		field: func(c *Config) any { return &c.RemoteOpen }},
+	{Name: "remoteCommand", Type: TypeString, Description: "Command run with {url} when remoteOpen is command (e.g. a script that forwards it to your workstation)",
+		field: func(c *Config) any { return &c.RemoteCommand }},
+	{Name: "clipboard", Type: TypeEnum, Default: "auto", Description: "Clipboard tool used for copying (auto detects wl-copy, xclip, xsel, pbcopy, PowerShell/clip.exe, then OSC 52)",
+		field: func(c *Config) any { return &c.Clipboard }},
+	{Name: "fzfOptions", Type: TypeString, Description: "Extra arguments passed to fzf by pick",
+		field: func(c *Config) any { return &c.FzfOptions }},
+	{Name: "serverTagFilters", Type: TypeBool, Default: "false", Description: "API understands comma-separated tags plus any_tags, exclude_tags and untagged parameters",
+		field: func(c *Config) any { return &c.ServerTagFilters }},
+	{Name: "timeout", Type: TypeDuration, Default: "12s", Description: "HTTP timeout for API requests",
+		field: func(c *Config) any { return &c.Timeout }},
+	{Name: "urlRules", Type: TypeList, Default: urlnorm.DefaultRules, Choices: urlnorm.RuleNames, Description: "Comma-separated URL clean-ups applied by add and markdex normalize (tracking drops utm_* and similar, host lowercases, port drops default ports, query sorts parameters, slash drops a trailing one)",
+		field: func(c *Config) any { return &c.URLRules }},
+	{Name: "trackingParams", Type: TypeString, Description: "More comma-separated query parameters removed by the tracking rule (* matches anything, e.g. ref,share_*)",
+		field: func(c *Config) any { return &c.TrackingParams }},
+}
+
+// Table declares a config table of user-named string values, addressed on the command
+// line as "<table>.<name>" (e.g. "templates.compact").
+type Table struct {
+	Name        string
+	Description string
+	Validate    func(value string) error
+
+	// field returns a pointer to the Config map backing this table.
+	field func(c *Config) *map[string]string
+}
+
+// Tables lists the named-value tables understood by markdex.
+var Tables = []Table{
+	{Name: "templates", Description: "Named --format templates, used as --format <name>",
+		field: func(c *Config) *map[string]string { return &c.Templates }},
+	{Name: "pickKeys", Description: "fzf keys for pick actions (copy, edit, delete, tag, reload, ai), or none to disable one",
+		Validate: func(v string) error {
+			if !fzfKey.MatchString(v) {
+				return fmt.Errorf("%q is not an fzf key name such as ctrl-e or alt-t", v)
+			}
+			return nil
+		},
+		field: func(c *Config) *map[string]string { return &c.PickKeys }},
+}
+
+var fzfKey = regexp.MustCompile(`^[a-z0-9-]+$`)
+
+// SetChoices sets the allowed values of an enum key whose values are defined by another
+// package (the clipboard tools); cmd registers them so config does not import it.
+func SetChoices(name string, choices []string) {
+	for i := range Schema {
+		if strings.EqualFold(Schema[i].Name, name) {
+			Schema[i].Choices = choices
+		}
+	}
+}
+
+// SetValidator sets how the values of a table are validated (templates are parsed by the
+// output package, which cmd registers).
+func SetValidator(name string, validate func(value string) error) {
+	for i := range Tables {
+		if strings.EqualFold(Tables[i].Name, name) {
+			Tables[i].Validate = validate
+		}
+	}
+}
+
+// LookupTable finds a table by name (case-insensitive).
+func LookupTable(name string) (Table, bool) {
+	for _, t := range Tables {
+		if strings.EqualFold(t.Name, name) {
+			return t, true
+		}
+	}
+	return Table{}, false
+}
+
+// Entries returns the table's values in c.
+func (t Table) Entries(c *Config) map[string]string { return *t.field(c) }
+
+// Lookup finds a schema key by name (case-insensitive, as Viper lowercases keys).
+func Lookup(name string) (Key, bool) {
+	for _, k := range Schema {
+		if strings.EqualFold(k.Name, name) {
+			return k, true
+		}
+	}
+	return Key{}, false
+}
+
+// Parse validates raw and converts it to the Go value for this key.
+func (k Key) Parse(raw string) (any, error) {
+	raw = strings.TrimSpace(raw)
+	switch k.Type {
+	case TypeURL:
+		if raw == "" {
+			return "", nil
+		}
+		u, err := url.Parse(raw)
+		if err != nil {
+			return nil, err
+		}
+		if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return nil, fmt.Errorf("%q is not an absolute http(s) URL", raw)
+		}
+		return strings.TrimRight(raw, "/"), nil
+	case TypeDuration:
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not a duration (e.g. 30s, 5m, 1h)", raw)
+		}
+		if d < 0 {
+			return nil, errors.New("duration must not be negative")
+		}
+		return d, nil
+	case TypeInt:
+		n, err := strconv.Atoi(raw)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not an integer", raw)
+		}
+		return n, nil
+	case TypeBool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not a boolean", raw)
+		}
+		return b, nil
+	case TypeEnum:
+		if len(k.Choices) == 0 { // not registered: anything goes
+			return raw, nil
+		}
+		for _, c := range k.Choices {
+			if strings.EqualFold(c, raw) {
+				return c, nil
+			}
+		}
+		return nil, fmt.Errorf("%q is not one of %s", raw, strings.Join(k.Choices, ", "))
+	case TypeList:
+		var items []string
+		for _, item := range strings.Split(raw, ",") {
+			if item = strings.TrimSpace(item); item == "" {
+				continue
+			}
+			v, err := Key{Type: TypeEnum, Choices: k.Choices}.Parse(item)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, v.(string))
+		}
+		return strings.Join(items, ","), nil
+	default:
+		return raw, nil
+	}
+}
+
+// assign stores a parsed value into the Config field backing k.
+func (k Key) assign(c *Config, v any) {
+	switch p := k.field(c).(type) {
+	case *string:
+		*p = v.(string)
+	case *time.Duration:
+		*p = v.(time.Duration)
+	case *int:
+		*p = v.(int)
+	case *bool:
+		*p = v.(bool)
+	}
+}
+
+// Value returns the current value of k in c, formatted as it would be written to config.toml.
+func (k Key) Value(c *Config) string {
+	switch p := k.field(c).(type) {
+	case *string:
+		return *p
+	case *time.Duration:
+		return p.String()
+	case *int:
+		return strconv.Itoa(*p)
+	case *bool:
+		return strconv.FormatBool(*p)
+	}
+	return ""
+}
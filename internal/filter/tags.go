@@ -0,0 +1,100 @@
+// Package filter selects bookmarks client-side and translates filters to API query parameters.
+package filter
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/amaterasu/markdex-cli/internal/api"
+)
+
+// Tags is a tag filter: a bookmark matches when it has every tag in All, at least one
+// tag in Any (if given), none of Exclude, and no tags at all when Untagged is set.
+// Tags are compared case-insensitively.
+type Tags struct {
+	All      []string
+	Any      []string
+	Exclude  []string
+	Untagged bool
+}
+
+// Empty reports whether the filter has no criteria.
+func (t Tags) Empty() bool {
+	return len(t.All) == 0 && len(t.Any) == 0 && len(t.Exclude) == 0 && !t.Untagged
+}
+
+// Match reports whether b satisfies the filter.
+func (t Tags) Match(b api.Bookmark) bool {
+	if t.Untagged && len(b.Tags) > 0 {
+		return false
+	}
+	for _, want := range t.All {
+		if !hasTag(b, want) {
+			return false
+		}
+	}
+	if len(t.Any) > 0 {
+		found := false
+		for _, want := range t.Any {
+			if hasTag(b, want) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	for _, no := range t.Exclude {
+		if hasTag(b, no) {
+			return false
+		}
+	}
+	return true
+}
+
+// Apply returns the bookmarks matching the filter.
+func (t Tags) Apply(items []api.Bookmark) []api.Bookmark {
+	if t.Empty() {
+		return items
+	}
+	out := items[:0:0]
+	for _, b := range items {
+		if t.Match(b) {
+			out = append(out, b)
+		}
+	}
+	return out
+}
+
+// Simple reports whether the filter is a single required tag, the only form every API
+// version understands (as tags=<tag>).
+func (t Tags) Simple() bool {
+	return len(t.All) == 1 && len(t.Any) == 0 && len(t.Exclude) == 0 && !t.Untagged
+}
+
+// Query adds the filter to q as API parameters: tags (all, comma separated), any_tags,
+// exclude_tags and untagged. Only the single-tag form is understood by every server.
+func (t Tags) Query(q url.Values) {
+	if len(t.All) > 0 {
+		q.Set("tags", strings.Join(t.All, ","))
+	}
+	if len(t.Any) > 0 {
+		q.Set("any_tags", strings.Join(t.Any, ","))
+	}
+	if len(t.Exclude) > 0 {
+		q.Set("exclude_tags", strings.Join(t.Exclude, ","))
+	}
+	if t.Untagged {
+		q.Set("untagged", "true")
+	}
+}
+
+func hasTag(b api.Bookmark, tag string) bool {
+	for _, t := range b.Tags {
+		if strings.EqualFold(t, tag) {
+			return true
+		}
+	}
+	return false
+}
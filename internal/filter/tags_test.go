@@ -0,0 +1,70 @@
+package filter
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/amaterasu/markdex-cli/internal/api"
+)
+
+func TestTagsApply(t *testing.T) {
+	items := []api.Bookmark{
+		{Hash: "a", Tags: []string{"Go", "web"}},
+		{Hash: "b", Tags: []string{"go"}},
+		{Hash: "c", Tags: []string{"rust", "web"}},
+		{Hash: "d"},
+	}
+	tests := []struct {
+		name string
+		f    Tags
+		want string
+	}{
+		{"empty", Tags{}, "abcd"},
+		{"all, any case", Tags{All: []string{"GO"}}, "ab"},
+		{"all of several", Tags{All: []string{"go", "web"}}, "a"},
+		{"any", Tags{Any: []string{"rust", "web"}}, "ac"},
+		{"exclude", Tags{Exclude: []string{"web"}}, "bd"},
+		{"all and exclude", Tags{All: []string{"go"}, Exclude: []string{"web"}}, "b"},
+		{"any and exclude", Tags{Any: []string{"go", "rust"}, Exclude: []string{"GO"}}, "c"},
+		{"untagged", Tags{Untagged: true}, "d"},
+		{"untagged and a tag", Tags{Untagged: true, All: []string{"go"}}, ""},
+		{"unknown tag", Tags{All: []string{"python"}}, ""},
+	}
+	for _, tt := range tests {
+		var got []string
+		for _, b := range tt.f.Apply(items) {
+			got = append(got, b.Hash)
+		}
+		if strings.Join(got, "") != tt.want {
+			t.Errorf("%s: Apply = %q, want %q", tt.name, strings.Join(got, ""), tt.want)
+		}
+	}
+}
+
+func TestTagsQuery(t *testing.T) {
+	tests := []struct {
+		f      Tags
+		simple bool
+		want   string
+	}{
+		{Tags{}, false, ""},
+		{Tags{All: []string{"go"}}, true, "tags=go"},
+		{Tags{All: []string{"go", "web"}}, false, "tags=go%2Cweb"},
+		{Tags{Any: []string{"a", "b"}, Exclude: []string{"c"}}, false, "any_tags=a%2Cb&exclude_tags=c"},
+		{Tags{Untagged: true}, false, "untagged=true"},
+	}
+	for _, tt := range tests {
+		q := url.Values{}
+		tt.f.Query(q)
+		if got := q.Encode(); got != tt.want {
+			t.Errorf("%+v: Query = %q, want %q", tt.f, got, tt.want)
+		}
+		if tt.f.Simple() != tt.simple {
+			t.Errorf("%+v: Simple = %v", tt.f, !tt.simple)
+		}
+		if tt.f.Empty() != (tt.want == "") {
+			t.Errorf("%+v: Empty = %v", tt.f, tt.f.Empty())
+		}
+	}
+}
@@ -0,0 +1,114 @@
+// Package history keeps a local log of opened bookmarks, used to rank them by frecency
+// (how often and how recently each was opened).
+package history
+
+import (
+	"bufio"
+	"encoding/json"
+	"math"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/amaterasu/markdex-cli/internal/util"
+)
+
+// Entry records one open of a bookmark.
+type Entry struct {
+	Hash    string    `json:"hash" yaml:"hash"`
+	Title   string    `json:"title,omitempty" yaml:"title,omitempty"`
+	URL     string    `json:"url" yaml:"url"`
+	Time    time.Time `json:"time" yaml:"time"`
+	Command string    `json:"command,omitempty" yaml:"command,omitempty"` // e.g. "open" or "pick --copy"
+}
+
+// Path returns the history file: $XDG_STATE_HOME/markdex/history.jsonl, by default under
+// ~/.local/state.
+func Path() string { return filepath.Join(util.StateDir(), "history.jsonl") }
+
+// Record appends an entry (errors are ignored: history is best effort).
+func Record(e Entry) {
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+	p := Path()
+	_ = os.MkdirAll(filepath.Dir(p), 0o755)
+	f, err := os.OpenFile(p, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+	b, _ := json.Marshal(e)
+	_, _ = f.Write(append(b, '\n'))
+}
+
+// Load returns all entries, oldest first; a missing file is an empty history and
+// unreadable lines are skipped.
+func Load() ([]Entry, error) {
+	f, err := os.Open(Path())
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var out []Entry
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		var e Entry
+		if json.Unmarshal(sc.Bytes(), &e) == nil && e.Hash != "" {
+			out = append(out, e)
+		}
+	}
+	return out, sc.Err()
+}
+
+// Clear deletes the history.
+func Clear() error {
+	err := os.Remove(Path())
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
+
+// visitWeight scores one open by its age, like Firefox's frecency buckets.
+func visitWeight(age time.Duration) float64 {
+	const day = 24 * time.Hour
+	switch {
+	case age < 4*day:
+		return 100
+	case age < 14*day:
+		return 70
+	case age < 31*day:
+		return 50
+	case age < 90*day:
+		return 30
+	}
+	return 10
+}
+
+// Frecency scores bookmarks by hash from the local opens in entries.
+func Frecency(entries []Entry, now time.Time) map[string]float64 {
+	scores := map[string]float64{}
+	for _, e := range entries {
+		scores[e.Hash] += visitWeight(now.Sub(e.Time))
+	}
+	return scores
+}
+
+// UsageWeight turns the server's usage counts into score points: the user's own count
+// stands for opens on other machines or before history was kept, and the opens by
+// everyone else (total minus own, when the API reports a total) count a quarter as much.
+// Both have diminishing returns.
+func UsageWeight(usage, total int) float64 {
+	w := 0.0
+	if usage > 0 {
+		w += 20 * math.Log1p(float64(usage))
+	}
+	if others := total - max(usage, 0); others > 0 {
+		w += 5 * math.Log1p(float64(others))
+	}
+	return w
+}
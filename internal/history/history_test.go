@@ -0,0 +1,97 @@
+package history
+
+import (
+	"math"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestFrecency(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	ago := func(d time.Duration) time.Time { return now.Add(-d) }
+	const day = 24 * time.Hour
+	entries := []Entry{
+		{Hash: "a", Time: ago(time.Hour)},
+		{Hash: "a", Time: ago(5 * day)},
+		{Hash: "b", Time: ago(20 * day)},
+		{Hash: "b", Time: ago(60 * day)},
+		{Hash: "b", Time: ago(400 * day)},
+		{Hash: "c", Time: now.Add(time.Hour)}, // clock skew: counts as recent
+	}
+	want := map[string]float64{"a": 100 + 70, "b": 50 + 30 + 10, "c": 100}
+	got := Frecency(entries, now)
+	if len(got) != len(want) {
+		t.Errorf("Frecency = %v, want %v", got, want)
+	}
+	for h, w := range want {
+		if got[h] != w {
+			t.Errorf("Frecency[%s] = %v, want %v", h, got[h], w)
+		}
+	}
+	if len(Frecency(nil, now)) != 0 {
+		t.Error("Frecency of no history is not empty")
+	}
+}
+
+func TestUsageWeight(t *testing.T) {
+	tests := []struct {
+		usage, total int
+		want         float64
+	}{
+		{0, 0, 0},
+		{-1, 0, 0},
+		{1, 0, 20 * math.Log(2)},
+		{1, 1, 20 * math.Log(2)}, // total includes the user's own
+		{0, 3, 5 * math.Log(4)},
+		{3, 7, 20*math.Log(4) + 5*math.Log(5)},
+		{5, 2, 20 * math.Log(6)}, // an inconsistent total adds nothing
+	}
+	for _, tt := range tests {
+		if got := UsageWeight(tt.usage, tt.total); math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("UsageWeight(%d, %d) = %v, want %v", tt.usage, tt.total, got, tt.want)
+		}
+	}
+	// diminishing returns: doubling usage adds less than double the weight
+	if UsageWeight(20, 0) >= 2*UsageWeight(10, 0) {
+		t.Error("UsageWeight grows linearly")
+	}
+}
+
+func TestRecordLoad(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	if entries, err := Load(); err != nil || len(entries) != 0 {
+		t.Fatalf("Load of no file = %v, %v", entries, err)
+	}
+	when := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	Record(Entry{Hash: "a", URL: "https://a.example/", Time: when, Command: "open"})
+	Record(Entry{Hash: "b", URL: "https://b.example/"})
+
+	f, err := os.OpenFile(Path(), os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteString("not json\n{\"url\":\"no hash\"}\n")
+	f.Close()
+
+	entries, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 || entries[0].Hash != "a" || !entries[0].Time.Equal(when) || entries[0].Command != "open" || entries[1].Hash != "b" {
+		t.Fatalf("Load = %+v", entries)
+	}
+	if entries[1].Time.IsZero() || time.Since(entries[1].Time) > time.Minute {
+		t.Errorf("Record did not set the time: %v", entries[1].Time)
+	}
+
+	if err := Clear(); err != nil {
+		t.Fatal(err)
+	}
+	if err := Clear(); err != nil {
+		t.Errorf("Clear with no history: %v", err)
+	}
+	if entries, _ := Load(); len(entries) != 0 {
+		t.Errorf("after Clear, Load = %+v", entries)
+	}
+}
@@ -0,0 +1,111 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/mattn/go-runewidth"
+
+	"github.com/amaterasu/markdex-cli/internal/api"
+)
+
+// Bookmarks renders a bookmark listing. The table format shows the position (for
+// markdex open <n>), short hash, title and tags; csv, tsv and markdown include every field.
+func Bookmarks(w io.Writer, f Format, items []api.Bookmark) error {
+	return numbered(w, f, items, 1)
+}
+
+// numbered is Bookmarks with the table positions starting at first.
+func numbered(w io.Writer, f Format, items []api.Bookmark, first int) error {
+	if items == nil {
+		items = []api.Bookmark{}
+	}
+	if f == Table && len(items) == 0 {
+		_, err := fmt.Fprintln(w, "No matching entries found.")
+		return err
+	}
+	if f == Table {
+		return writeBookmarkTable(w, DetectTerm(w), items, first)
+	}
+	t := Tabular{Header: []string{"HASH", "TITLE", "URL", "TAGS", "SECTION", "SOURCE_FILE", "USAGE"}}
+	for _, b := range items {
+		t.Rows = append(t.Rows, []string{b.Hash, b.Title, b.URL, strings.Join(b.Tags, ","), b.Section, b.SourceFile, strconv.Itoa(b.Usage)})
+	}
+	return Render(w, f, items, t)
+}
+
+// writeBookmarkTable prints position, hash, title and tags sized to the terminal: the title
+// column takes whatever the other columns leave (40 columns when not on a terminal).
+// On a color terminal hashes and tags are colorized and titles link to the bookmark URL.
+func writeBookmarkTable(w io.Writer, t Term, items []api.Bookmark, first int) error {
+	const gap = 2
+	numW := max(Width("#"), len(strconv.Itoa(first+len(items)-1)))
+	hashW, tagsW := Width("HASH"), Width("TAGS")
+	rows := make([][3]string, len(items))
+	hashes := make([]string, len(items))
+	for i, b := range items {
+		var plain string
+		hashes[i], plain = hashCell(t, b.Hash)
+		rows[i] = [3]string{plain, flatten(b.Title), strings.Join(b.Tags, ",")}
+		hashW = max(hashW, Width(rows[i][0]))
+		tagsW = max(tagsW, Width(rows[i][2]))
+	}
+	titleW := 40
+	if t.Width > 0 {
+		// Give tags at most a quarter of the line, the title the rest (but never under 20).
+		tagsW = min(tagsW, max(t.Width/4, Width("TAGS")))
+		titleW = max(t.Width-numW-hashW-tagsW-3*gap, 20)
+	}
+
+	var b strings.Builder
+	header := pad("#", "#", numW+gap) + pad("HASH", "HASH", hashW+gap) + pad("TITLE", "TITLE", titleW+gap) + "TAGS"
+	b.WriteString(t.paint(header, color.Faint) + "\n")
+	for i, r := range rows {
+		title := Truncate(r[1], titleW)
+		tags := r[2]
+		if t.Width > 0 {
+			tags = Truncate(tags, tagsW)
+		}
+		num := strconv.Itoa(first + i)
+		b.WriteString(spaces(numW-len(num)) + t.paint(num, color.Faint) + spaces(gap))
+		b.WriteString(pad(hashes[i], r[0], hashW+gap))
+		if tags == "" {
+			b.WriteString(t.link(title, items[i].URL))
+		} else {
+			b.WriteString(pad(t.link(title, items[i].URL), title, titleW+gap))
+			b.WriteString(t.paint(tags, color.FgCyan))
+		}
+		b.WriteString("\n")
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// Bookmark renders a single bookmark; the table format prints one field per line.
+func Bookmark(w io.Writer, f Format, b api.Bookmark) error {
+	fields := [][]string{
+		{"Title", b.Title},
+		{"URL", b.URL},
+		{"Hash", b.Hash},
+		{"Tags", strings.Join(b.Tags, ", ")},
+		{"Section", b.Section},
+		{"Source file", b.SourceFile},
+		{"Line", strconv.Itoa(b.Line)},
+		{"Usage", strconv.Itoa(b.Usage)},
+		{"Description", b.Description},
+	}
+	if f == Table {
+		for _, kv := range fields {
+			kv[0] += ":"
+		}
+	}
+	return Render(w, f, b, Tabular{Header: []string{"FIELD", "VALUE"}, Rows: fields, NoHeader: true})
+}
+
+// Truncate shortens s to at most n terminal columns, marking the cut with an ellipsis.
+func Truncate(s string, n int) string {
+	return runewidth.Truncate(s, n, "…")
+}
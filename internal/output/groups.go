@@ -0,0 +1,58 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/fatih/color"
+
+	"github.com/amaterasu/markdex-cli/internal/api"
+)
+
+// Group is a named subset of a listing (e.g. all bookmarks with one tag).
+type Group struct {
+	Name  string         `json:"group" yaml:"group"`
+	Items []api.Bookmark `json:"items" yaml:"items"`
+}
+
+// Groups renders grouped bookmarks: headings followed by a table (table and markdown),
+// a leading GROUP column (csv and tsv) or a list of {group, items} objects (json, ndjson, yaml).
+func Groups(w io.Writer, f Format, groups []Group) error {
+	if groups == nil {
+		groups = []Group{}
+	}
+	switch f {
+	case JSON, NDJSON, YAML:
+		return Render(w, f, groups, Tabular{})
+	case CSV, TSV:
+		t := Tabular{Header: []string{"GROUP", "HASH", "TITLE", "URL", "TAGS", "SECTION", "SOURCE_FILE", "USAGE"}}
+		for _, g := range groups {
+			for _, b := range g.Items {
+				t.Rows = append(t.Rows, []string{g.Name, b.Hash, b.Title, b.URL, strings.Join(b.Tags, ","), b.Section, b.SourceFile, strconv.Itoa(b.Usage)})
+			}
+		}
+		return Render(w, f, nil, t)
+	}
+	if len(groups) == 0 {
+		return Bookmarks(w, f, nil)
+	}
+	term := DetectTerm(w)
+	first := 1 // positions continue across groups
+	for i, g := range groups {
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+		if f == Markdown {
+			fmt.Fprintf(w, "## %s\n\n", g.Name)
+		} else {
+			fmt.Fprintf(w, "%s %s\n", term.paint(g.Name, color.Bold, color.FgMagenta), term.paint("("+strconv.Itoa(len(g.Items))+")", color.Faint))
+		}
+		if err := numbered(w, f, g.Items, first); err != nil {
+			return err
+		}
+		first += len(g.Items)
+	}
+	return nil
+}
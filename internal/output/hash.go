@@ -0,0 +1,99 @@
+package output
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/fatih/color"
+)
+
+// MinHashPrefix is the shortest hash prefix shown or accepted for lookups.
+const MinHashPrefix = 3
+
+// SafeHashPrefix is the prefix length to show when uniqueness can only be checked against
+// part of the collection, as git does by default.
+const SafeHashPrefix = 7
+
+// prefixLen maps a lowercased hash to the length of its shortest unique prefix.
+// It is filled by IndexHashes; until then ShortHash falls back to seven characters.
+var prefixLen map[string]int
+
+// IndexHashes computes, like git, the shortest prefix (at least minLen characters) that
+// identifies each hash within the collection. A prefix of digits only is extended to its
+// first letter, as markdex open reads numbers as listing positions. Empty hashes are ignored.
+func IndexHashes(hashes []string, minLen int) {
+	sorted := make([]string, 0, len(hashes))
+	for _, h := range hashes {
+		if h != "" {
+			sorted = append(sorted, strings.ToLower(h))
+		}
+	}
+	sort.Strings(sorted)
+	prefixLen = make(map[string]int, len(sorted))
+	for i, h := range sorted {
+		// In sorted order the longest shared prefix is always with a neighbour.
+		n := 0
+		if i > 0 {
+			n = max(n, commonPrefix(h, sorted[i-1]))
+		}
+		if i < len(sorted)-1 {
+			n = max(n, commonPrefix(h, sorted[i+1]))
+		}
+		n = min(max(n+1, minLen), len(h))
+		for n < len(h) && allDigits(h[:n]) {
+			n++
+		}
+		prefixLen[h] = n
+	}
+}
+
+func allDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return s != ""
+}
+
+func commonPrefix(a, b string) int {
+	n := 0
+	for n < len(a) && n < len(b) && a[n] == b[n] {
+		n++
+	}
+	return n
+}
+
+// uniqueLen returns the unique prefix length of h, or 0 when h has not been indexed.
+func uniqueLen(h string) int {
+	return prefixLen[strings.ToLower(h)]
+}
+
+// ShortHash returns the shortest unique prefix of h when the collection has been indexed,
+// otherwise its first SafeHashPrefix characters. Hashes shorter than that are returned whole.
+func ShortHash(h string) string {
+	if n := uniqueLen(h); n > 0 {
+		return h[:n]
+	}
+	if len(h) > SafeHashPrefix {
+		return h[:SafeHashPrefix]
+	}
+	return h
+}
+
+// hashCell formats a hash for the table: the unique prefix, and on a color terminal the
+// following characters up to seven dimmed for context. Missing hashes show as "-".
+func hashCell(t Term, h string) (styled, plain string) {
+	if h == "" {
+		return t.paint("-", color.Faint), "-"
+	}
+	short := ShortHash(h)
+	if !t.Color {
+		return short, short
+	}
+	rest := ""
+	if len(short) < 7 && len(h) > len(short) {
+		rest = h[len(short):min(7, len(h))]
+	}
+	return t.paint(short, color.FgYellow, color.Bold) + t.paint(rest, color.Faint), short + rest
+}
@@ -0,0 +1,84 @@
+package output
+
+import "testing"
+
+func TestShortHash(t *testing.T) {
+	tests := []struct {
+		name   string
+		hashes []string // nil: not indexed
+		minLen int
+		want   map[string]string
+	}{
+		{
+			name:   "not indexed",
+			hashes: nil,
+			want:   map[string]string{"abcdef0123": "abcdef0", "abc": "abc", "": ""},
+		},
+		{
+			name:   "unique at minimum length",
+			hashes: []string{"abc111", "def222"},
+			minLen: 3,
+			want:   map[string]string{"abc111": "abc", "def222": "def"},
+		},
+		{
+			name:   "shared prefix",
+			hashes: []string{"abcd11", "abce22", "abf333"},
+			minLen: 3,
+			want:   map[string]string{"abcd11": "abcd", "abce22": "abce", "abf333": "abf"},
+		},
+		{
+			name:   "case-insensitive",
+			hashes: []string{"ABCD11", "abce22"},
+			minLen: 3,
+			want:   map[string]string{"ABCD11": "ABCD", "abce22": "abce"},
+		},
+		{
+			name:   "all-digit prefix extended to the first letter",
+			hashes: []string{"123456789a", "abc000"},
+			minLen: 3,
+			want:   map[string]string{"123456789a": "123456789a", "abc000": "abc"},
+		},
+		{
+			name:   "all-digit prefix collision",
+			hashes: []string{"1234a0", "1235b0", "12f000"},
+			minLen: 3,
+			want:   map[string]string{"1234a0": "1234a", "1235b0": "1235b", "12f000": "12f"},
+		},
+		{
+			name:   "all digits",
+			hashes: []string{"123456", "123457"},
+			minLen: 3,
+			want:   map[string]string{"123456": "123456", "123457": "123457"},
+		},
+		{
+			name:   "safe length without a cache",
+			hashes: []string{"abcdef0123", "0123456789abc"},
+			minLen: SafeHashPrefix,
+			want:   map[string]string{"abcdef0123": "abcdef0", "0123456789abc": "0123456789a"},
+		},
+		{
+			name:   "shorter than the minimum",
+			hashes: []string{"ab", "abcd"},
+			minLen: 3,
+			want:   map[string]string{"ab": "ab", "abcd": "abc"},
+		},
+		{
+			name:   "duplicates and empty hashes",
+			hashes: []string{"abc111", "abc111", "", "abd222"},
+			minLen: 3,
+			want:   map[string]string{"abc111": "abc111", "abd222": "abd"},
+		},
+	}
+	defer func() { prefixLen = nil }()
+	for _, tt := range tests {
+		prefixLen = nil
+		if tt.hashes != nil {
+			IndexHashes(tt.hashes, tt.minLen)
+		}
+		for h, want := range tt.want {
+			if got := ShortHash(h); got != want {
+				t.Errorf("%s: ShortHash(%q) = %q, want %q", tt.name, h, got, want)
+			}
+		}
+	}
+}
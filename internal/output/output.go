@@ -0,0 +1,209 @@
+// Package output renders command results in the formats selected by --output.
+package output
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+
+	"github.com/fatih/color"
+	"gopkg.in/yaml.v3"
+)
+
+// Format is an output format name as accepted by --output.
+type Format string
+
+const (
+	Table    Format = "table"
+	JSON     Format = "json"
+	NDJSON   Format = "ndjson"
+	CSV      Format = "csv"
+	TSV      Format = "tsv"
+	Markdown Format = "markdown"
+	YAML     Format = "yaml"
+)
+
+// Formats lists every supported format in the order shown in help text.
+var Formats = []Format{Table, JSON, NDJSON, CSV, TSV, Markdown, YAML}
+
+// Parse resolves a format name (case-insensitive; "md" and "yml" are accepted as aliases).
+func Parse(s string) (Format, error) {
+	switch f := Format(strings.ToLower(strings.TrimSpace(s))); f {
+	case "":
+		return Table, nil
+	case "md":
+		return Markdown, nil
+	case "yml":
+		return YAML, nil
+	default:
+		for _, known := range Formats {
+			if f == known {
+				return f, nil
+			}
+		}
+	}
+	return "", fmt.Errorf("unknown output format %q (valid: %s)", s, Names())
+}
+
+// Names returns the supported format names joined for help and error messages.
+func Names() string {
+	names := make([]string, len(Formats))
+	for i, f := range Formats {
+		names[i] = string(f)
+	}
+	return strings.Join(names, ", ")
+}
+
+// Tabular is the row/column view of a result used by the table, csv, tsv and markdown formats.
+type Tabular struct {
+	Header []string
+	Rows   [][]string
+	// NoHeader suppresses the header row in the table format (used for key/value views).
+	NoHeader bool
+}
+
+// Render writes v in format f. Structured formats (json, ndjson, yaml) encode v directly;
+// the others render t. For ndjson a slice is written one element per line.
+func Render(w io.Writer, f Format, v any, t Tabular) error {
+	switch f {
+	case JSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(v)
+	case NDJSON:
+		enc := json.NewEncoder(w)
+		rv := reflect.ValueOf(v)
+		if rv.Kind() != reflect.Slice {
+			return enc.Encode(v)
+		}
+		for i := 0; i < rv.Len(); i++ {
+			if err := enc.Encode(rv.Index(i).Interface()); err != nil {
+				return err
+			}
+		}
+		return nil
+	case YAML:
+		enc := yaml.NewEncoder(w)
+		enc.SetIndent(2)
+		if err := enc.Encode(v); err != nil {
+			return err
+		}
+		return enc.Close()
+	case CSV:
+		return writeDelimited(w, ',', t)
+	case TSV:
+		return writeDelimited(w, '\t', t)
+	case Markdown:
+		return writeMarkdown(w, t)
+	default:
+		return writeTable(w, t)
+	}
+}
+
+func writeTable(w io.Writer, t Tabular) error {
+	term := DetectTerm(w)
+	rows := t.Rows
+	if !t.NoHeader && len(t.Header) > 0 {
+		rows = append([][]string{t.Header}, rows...)
+	}
+	var widths []int
+	for _, r := range rows {
+		for i, c := range r {
+			if i >= len(widths) {
+				widths = append(widths, 0)
+			}
+			widths[i] = max(widths[i], Width(flatten(c)))
+		}
+	}
+	var b strings.Builder
+	for ri, r := range rows {
+		var line strings.Builder
+		for i, c := range r {
+			c = flatten(c)
+			if i < len(r)-1 {
+				c = pad(c, c, widths[i]+2)
+			}
+			line.WriteString(c)
+		}
+		if ri == 0 && !t.NoHeader && len(t.Header) > 0 {
+			b.WriteString(term.paint(line.String(), color.Faint))
+		} else {
+			b.WriteString(line.String())
+		}
+		b.WriteByte('\n')
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func writeDelimited(w io.Writer, sep rune, t Tabular) error {
+	if sep == '\t' {
+		// TSV has no quoting; keep every record on one line with no stray separators.
+		var b strings.Builder
+		for _, r := range append([][]string{t.Header}, t.Rows...) {
+			if len(r) == 0 {
+				continue
+			}
+			cells := make([]string, len(r))
+			for i, c := range r {
+				cells[i] = flatten(c)
+			}
+			b.WriteString(strings.Join(cells, "\t"))
+			b.WriteByte('\n')
+		}
+		_, err := io.WriteString(w, b.String())
+		return err
+	}
+	cw := csv.NewWriter(w)
+	cw.Comma = sep
+	if len(t.Header) > 0 {
+		if err := cw.Write(t.Header); err != nil {
+			return err
+		}
+	}
+	if err := cw.WriteAll(t.Rows); err != nil {
+		return err
+	}
+	return cw.Error()
+}
+
+func writeMarkdown(w io.Writer, t Tabular) error {
+	cols := len(t.Header)
+	for _, r := range t.Rows {
+		if len(r) > cols {
+			cols = len(r)
+		}
+	}
+	if cols == 0 {
+		return nil
+	}
+	line := func(cells []string) string {
+		out := make([]string, cols)
+		for i := range out {
+			if i < len(cells) {
+				out[i] = strings.ReplaceAll(flatten(cells[i]), "|", `\|`)
+			}
+		}
+		return "| " + strings.Join(out, " | ") + " |\n"
+	}
+	var b strings.Builder
+	b.WriteString(line(t.Header))
+	sep := make([]string, cols)
+	for i := range sep {
+		sep[i] = "---"
+	}
+	b.WriteString("|" + strings.Join(sep, "|") + "|\n")
+	for _, r := range t.Rows {
+		b.WriteString(line(r))
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// flatten replaces tabs and line breaks so a value stays within one cell.
+func flatten(s string) string {
+	return strings.NewReplacer("\t", " ", "\r\n", " ", "\n", " ", "\r", " ").Replace(s)
+}
@@ -0,0 +1,86 @@
+package output
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Format
+		err  bool
+	}{
+		{"", Table, false},
+		{"json", JSON, false},
+		{" NDJSON ", NDJSON, false},
+		{"md", Markdown, false},
+		{"yml", YAML, false},
+		{"tsv", TSV, false},
+		{"xml", "", true},
+	}
+	for _, tt := range tests {
+		got, err := Parse(tt.in)
+		if got != tt.want || (err != nil) != tt.err {
+			t.Errorf("Parse(%q) = %q, %v; want %q", tt.in, got, err, tt.want)
+		}
+	}
+}
+
+func TestRender(t *testing.T) {
+	type row struct {
+		Name  string `json:"name" yaml:"name"`
+		Count int    `json:"count" yaml:"count"`
+	}
+	v := []row{{"a", 1}, {"b|c", 2}}
+	tab := Tabular{
+		Header: []string{"NAME", "NOTE"},
+		Rows:   [][]string{{"a", "one"}, {"b|c", "two,\n\tlines"}},
+	}
+	tests := []struct {
+		f    Format
+		want string
+	}{
+		{JSON, "[\n  {\n    \"name\": \"a\",\n    \"count\": 1\n  },\n  {\n    \"name\": \"b|c\",\n    \"count\": 2\n  }\n]\n"},
+		{NDJSON, "{\"name\":\"a\",\"count\":1}\n{\"name\":\"b|c\",\"count\":2}\n"},
+		{YAML, "- name: a\n  count: 1\n- name: b|c\n  count: 2\n"},
+		{CSV, "NAME,NOTE\na,one\nb|c,\"two,\n\tlines\"\n"},
+		{TSV, "NAME\tNOTE\na\tone\nb|c\ttwo,  lines\n"},
+		{Markdown, "| NAME | NOTE |\n|---|---|\n| a | one |\n| b\\|c | two,  lines |\n"},
+		{Table, "NAME  NOTE\na     one\nb|c   two,  lines\n"},
+	}
+	for _, tt := range tests {
+		var b bytes.Buffer
+		if err := Render(&b, tt.f, v, tab); err != nil {
+			t.Errorf("%s: %v", tt.f, err)
+			continue
+		}
+		if b.String() != tt.want {
+			t.Errorf("%s: got\n%q\nwant\n%q", tt.f, b.String(), tt.want)
+		}
+	}
+
+	// a single value is one line of ndjson
+	var b bytes.Buffer
+	if err := Render(&b, NDJSON, row{"a", 1}, Tabular{}); err != nil || b.String() != "{\"name\":\"a\",\"count\":1}\n" {
+		t.Errorf("ndjson of a struct = %q, %v", b.String(), err)
+	}
+}
+
+func TestBookmarksEmpty(t *testing.T) {
+	tests := []struct {
+		f    Format
+		want string
+	}{
+		{Table, "No matching entries found.\n"},
+		{JSON, "[]\n"},
+		{NDJSON, ""},
+		{CSV, "HASH,TITLE,URL,TAGS,SECTION,SOURCE_FILE,USAGE\n"},
+	}
+	for _, tt := range tests {
+		var b bytes.Buffer
+		if err := Bookmarks(&b, tt.f, nil); err != nil || b.String() != tt.want {
+			t.Errorf("%s: Bookmarks(nil) = %q, %v; want %q", tt.f, b.String(), err, tt.want)
+		}
+	}
+}
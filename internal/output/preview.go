@@ -0,0 +1,43 @@
+package output
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/fatih/color"
+
+	"github.com/amaterasu/markdex-cli/internal/api"
+)
+
+// Preview describes b for a picker preview pane in lines of at most width columns. On a
+// color Term the title is bold, labels are dimmed and tags colored like in tables.
+func Preview(b api.Bookmark, width int, t Term) []string {
+	var lines []string
+	field := func(label, value string, attrs ...color.Attribute) {
+		if value == "" {
+			return
+		}
+		label += ":"
+		for i, l := range Wrap(label+" "+value, width) {
+			if i == 0 && strings.HasPrefix(l, label) {
+				lines = append(lines, t.paint(label, color.Faint)+t.paint(l[len(label):], attrs...))
+				continue
+			}
+			lines = append(lines, t.paint(l, attrs...))
+		}
+	}
+	for _, l := range Wrap(strings.Join(strings.Fields(b.Title), " "), width) {
+		lines = append(lines, t.paint(l, color.Bold))
+	}
+	field("URL", b.URL, color.FgBlue)
+	field("Tags", strings.Join(b.Tags, ", "), color.FgCyan)
+	field("Hash", b.Hash, color.FgYellow)
+	field("Section", b.Section)
+	field("Source", b.SourceFile)
+	field("Usage", strconv.Itoa(b.Usage))
+	if b.Description != "" {
+		lines = append(lines, "")
+		lines = append(lines, Wrap(b.Description, width)...)
+	}
+	return lines
+}
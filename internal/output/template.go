@@ -0,0 +1,68 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/url"
+	"reflect"
+	"strings"
+	"text/template"
+)
+
+// TemplateFuncs are the helpers available to --format templates:
+//
+//	{{short .Hash}}          shortest unique prefix of a hash (see ShortHash)
+//	{{join ", " .Tags}}      join a list with a separator
+//	{{truncate 30 .Title}}   cut a string to n runes with an ellipsis
+//	{{host .URL}}            host name of a URL
+//	{{json .}}               compact JSON encoding of any value
+var TemplateFuncs = template.FuncMap{
+	"short":    ShortHash,
+	"join":     func(sep string, xs []string) string { return strings.Join(xs, sep) },
+	"truncate": func(n int, s string) string { return Truncate(s, n) },
+	"host":     Host,
+	"json": func(v any) (string, error) {
+		b, err := json.Marshal(v)
+		return string(b), err
+	},
+}
+
+// ParseTemplate compiles a --format template with TemplateFuncs.
+func ParseTemplate(text string) (*template.Template, error) {
+	return template.New("format").Funcs(TemplateFuncs).Option("missingkey=error").Parse(text)
+}
+
+// Execute renders t once per element of v (or once for v if it is not a slice),
+// writing a newline after each rendering.
+func Execute(w io.Writer, t *template.Template, v any) error {
+	var buf bytes.Buffer
+	one := func(x any) error {
+		buf.Reset()
+		if err := t.Execute(&buf, x); err != nil {
+			return err
+		}
+		buf.WriteByte('\n')
+		_, err := w.Write(buf.Bytes())
+		return err
+	}
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Slice {
+		return one(v)
+	}
+	for i := 0; i < rv.Len(); i++ {
+		if err := one(rv.Index(i).Interface()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Host returns the host name of a URL, or an empty string if it cannot be parsed.
+func Host(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return ""
+	}
+	return u.Hostname()
+}
@@ -0,0 +1,53 @@
+package output
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/amaterasu/markdex-cli/internal/api"
+)
+
+func TestExecute(t *testing.T) {
+	items := []api.Bookmark{
+		{Title: "Go blog", URL: "https://go.dev:443/blog?x=1", Hash: "abcdef0123", Tags: []string{"go", "news"}},
+		{Title: "Unicode, the long story", URL: "not a url\x7f", Hash: "fed"},
+	}
+	tests := []struct {
+		name string
+		text string
+		v    any
+		want string
+		err  bool
+	}{
+		{name: "fields", text: "{{.Title}}: {{.URL}}", v: items, want: "Go blog: https://go.dev:443/blog?x=1\nUnicode, the long story: not a url\x7f\n"},
+		{name: "short", text: "{{short .Hash}}", v: items, want: "abcdef0\nfed\n"},
+		{name: "join", text: `[{{join ", " .Tags}}]`, v: items, want: "[go, news]\n[]\n"},
+		{name: "truncate", text: "{{truncate 8 .Title}}", v: items, want: "Go blog\nUnicode…\n"},
+		{name: "host", text: "{{host .URL}}", v: items, want: "go.dev\n\n"},
+		{name: "json", text: "{{json .Tags}}", v: items, want: "[\"go\",\"news\"]\nnull\n"},
+		{name: "single value", text: "{{.Title}}", v: items[0], want: "Go blog\n"},
+		{name: "map", text: "{{.name}}", v: []map[string]string{{"name": "a"}}, want: "a\n"},
+		{name: "missing map key", text: "{{.nmae}}", v: []map[string]string{{"name": "a"}}, err: true},
+		{name: "unknown field", text: "{{.Titel}}", v: items, err: true},
+	}
+	for _, tt := range tests {
+		tmpl, err := ParseTemplate(tt.text)
+		if err != nil {
+			t.Errorf("%s: ParseTemplate: %v", tt.name, err)
+			continue
+		}
+		var b bytes.Buffer
+		err = Execute(&b, tmpl, tt.v)
+		if (err != nil) != tt.err {
+			t.Errorf("%s: Execute error = %v", tt.name, err)
+			continue
+		}
+		if !tt.err && b.String() != tt.want {
+			t.Errorf("%s: Execute = %q, want %q", tt.name, b.String(), tt.want)
+		}
+	}
+
+	if _, err := ParseTemplate("{{nope .Title}}"); err == nil {
+		t.Error("ParseTemplate accepted an unknown function")
+	}
+}
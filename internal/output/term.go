@@ -0,0 +1,116 @@
+package output
+
+import (
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/fatih/color"
+	"github.com/mattn/go-runewidth"
+	"golang.org/x/term"
+)
+
+// Term describes what the destination of the table format can display. Everything is
+// disabled when the writer is not a terminal or NO_COLOR is set.
+type Term struct {
+	TTY        bool
+	Width      int // columns; 0 when unknown
+	Color      bool
+	Hyperlinks bool // OSC 8 links
+}
+
+// DetectTerm inspects w (only *os.File can be a terminal).
+func DetectTerm(w io.Writer) Term {
+	f, ok := w.(*os.File)
+	if !ok || !term.IsTerminal(int(f.Fd())) {
+		return Term{}
+	}
+	t := Term{TTY: true}
+	if cols, _, err := term.GetSize(int(f.Fd())); err == nil && cols > 0 {
+		t.Width = cols
+	} else if cols, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && cols > 0 {
+		t.Width = cols
+	}
+	t.Color = os.Getenv("NO_COLOR") == "" && os.Getenv("TERM") != "dumb"
+	t.Hyperlinks = t.Color
+	return t
+}
+
+// paint applies attrs to s when colors are enabled.
+func (t Term) paint(s string, attrs ...color.Attribute) string {
+	if !t.Color || s == "" || len(attrs) == 0 {
+		return s
+	}
+	c := color.New(attrs...)
+	c.EnableColor()
+	return c.Sprint(s)
+}
+
+// link wraps text in an OSC 8 hyperlink to url when supported.
+func (t Term) link(text, url string) string {
+	if !t.Hyperlinks || url == "" {
+		return text
+	}
+	return "\x1b]8;;" + url + "\x1b\\" + text + "\x1b]8;;\x1b\\"
+}
+
+// Width returns the number of terminal columns s occupies (wide CJK and emoji count as two).
+func Width(s string) int { return runewidth.StringWidth(s) }
+
+// pad appends spaces to s (whose visible text is plain) up to n columns.
+func pad(s, plain string, n int) string {
+	if w := Width(plain); w < n {
+		return s + spaces(n-w)
+	}
+	return s
+}
+
+func spaces(n int) string {
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = ' '
+	}
+	return string(b)
+}
+
+// Wrap breaks s into lines of at most width columns at spaces (words longer than a line are
+// split). Line breaks in s are kept.
+func Wrap(s string, width int) []string {
+	if width <= 0 {
+		return []string{s}
+	}
+	var lines []string
+	for _, para := range strings.Split(strings.ReplaceAll(s, "\r\n", "\n"), "\n") {
+		line, lineW := "", 0
+		for _, word := range strings.Fields(para) {
+			for Width(word) > width {
+				// Split overlong words (e.g. URLs) across lines.
+				head := runewidth.Truncate(word, width, "")
+				if head == "" {
+					_, n := utf8.DecodeRuneInString(word)
+					head = word[:n]
+				}
+				if line != "" {
+					lines = append(lines, line)
+					line, lineW = "", 0
+				}
+				lines = append(lines, head)
+				word = word[len(head):]
+			}
+			w := Width(word)
+			switch {
+			case line == "":
+				line, lineW = word, w
+			case lineW+1+w <= width:
+				line, lineW = line+" "+word, lineW+1+w
+			default:
+				lines = append(lines, line)
+				line, lineW = word, w
+			}
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
@@ -0,0 +1,126 @@
+package output
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/amaterasu/markdex-cli/internal/api"
+)
+
+func TestWidth(t *testing.T) {
+	tests := map[string]int{
+		"":        0,
+		"abc":     3,
+		"日本語":     6,
+		"café":    4,
+		"go 🚀":    5,
+		"a\tb":    2, // control characters take no columns
+		"Ünïcödé": 7,
+	}
+	for s, want := range tests {
+		if got := Width(s); got != want {
+			t.Errorf("Width(%q) = %d, want %d", s, got, want)
+		}
+	}
+}
+
+func TestWrap(t *testing.T) {
+	tests := []struct {
+		s     string
+		width int
+		want  []string
+	}{
+		{"one two three", 0, []string{"one two three"}},
+		{"one two three", 7, []string{"one two", "three"}},
+		{"one two three", 3, []string{"one", "two", "thr", "ee"}},
+		{"first\n\nsecond", 20, []string{"first", "", "second"}},
+		{"see https://example.com/long/path now", 12, []string{"see", "https://exam", "ple.com/long", "/path now"}},
+		{"日本語のテキスト", 6, []string{"日本語", "のテキ", "スト"}},
+		{"  spaced   out  ", 20, []string{"spaced out"}},
+	}
+	for _, tt := range tests {
+		if got := Wrap(tt.s, tt.width); strings.Join(got, "|") != strings.Join(tt.want, "|") {
+			t.Errorf("Wrap(%q, %d) = %q, want %q", tt.s, tt.width, got, tt.want)
+		}
+	}
+}
+
+func TestBookmarkTable(t *testing.T) {
+	items := []api.Bookmark{
+		{Title: "A rather long title that will not fit in the column", URL: "https://a.example/", Hash: "abc1234567", Tags: []string{"go", "reading", "later"}},
+		{Title: "Short\nbroken", URL: "https://b.example/", Hash: "def7654321"},
+	}
+	tests := []struct {
+		name string
+		term Term
+		want string
+	}{
+		{
+			name: "not a terminal",
+			want: "#  HASH     TITLE                                     TAGS\n" +
+				"1  abc1234  A rather long title that will not fit i…  go,reading,later\n" +
+				"2  def7654  Short broken\n",
+		},
+		{
+			name: "narrow terminal",
+			term: Term{TTY: true, Width: 50},
+			want: "#  HASH     TITLE                     TAGS\n" +
+				"1  abc1234  A rather long title tha…  go,reading,…\n" +
+				"2  def7654  Short broken\n",
+		},
+		{
+			name: "hyperlinks",
+			term: Term{TTY: true, Width: 50, Hyperlinks: true},
+			want: "#  HASH     TITLE                     TAGS\n" +
+				"1  abc1234  \x1b]8;;https://a.example/\x1b\\A rather long title tha…\x1b]8;;\x1b\\  go,reading,…\n" +
+				"2  def7654  \x1b]8;;https://b.example/\x1b\\Short broken\x1b]8;;\x1b\\\n",
+		},
+	}
+	for _, tt := range tests {
+		var b strings.Builder
+		if err := writeBookmarkTable(&b, tt.term, items, 1); err != nil {
+			t.Fatal(err)
+		}
+		if b.String() != tt.want {
+			t.Errorf("%s: got\n%s\nwant\n%s", tt.name, b.String(), tt.want)
+		}
+		if tt.term.Width > 0 && !tt.term.Hyperlinks {
+			for _, line := range strings.Split(strings.TrimSuffix(b.String(), "\n"), "\n") {
+				if Width(line) > tt.term.Width {
+					t.Errorf("%s: %q is wider than %d columns", tt.name, line, tt.term.Width)
+				}
+			}
+		}
+	}
+}
+
+func TestPreview(t *testing.T) {
+	b := api.Bookmark{
+		Title:       "A  title\nthat wraps",
+		URL:         "https://example.com/a/long/path",
+		Tags:        []string{"go", "web"},
+		Hash:        "abc123",
+		Usage:       3,
+		Description: "First line.\nSecond line.",
+	}
+	want := []string{
+		"A title that wraps",
+		"URL:",
+		"https://example.co",
+		"m/a/long/path",
+		"Tags: go, web",
+		"Hash: abc123",
+		"Usage: 3",
+		"",
+		"First line.",
+		"Second line.",
+	}
+	if got := Preview(b, 18, Term{}); strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("Preview =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+
+	colored := Preview(api.Bookmark{Title: "T", Tags: []string{"go"}}, 40, Term{Color: true})
+	if len(colored) != 3 || !strings.Contains(colored[1], "\x1b[2mTags:\x1b[22m") || !strings.Contains(colored[1], "\x1b[36m go\x1b[0m") {
+		t.Errorf("colored Preview = %q", colored)
+	}
+}
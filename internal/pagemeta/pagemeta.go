@@ -0,0 +1,142 @@
+// Package pagemeta reads a web page's title, description and canonical URL from its
+// <head>, to prefill bookmarks added without AI.
+package pagemeta
+
+import (
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/charset"
+)
+
+// MaxSize is how much of a page Fetch reads; the metadata is expected near the top.
+const MaxSize = 1 << 20
+
+// Meta is what a page says about itself.
+type Meta struct {
+	Title       string // og:title, else <title>
+	Description string // meta description, else og:description
+	Canonical   string // <link rel="canonical">, else og:url (absolute)
+}
+
+// Fetch downloads rawURL (at most MaxSize bytes, within timeout) and parses its metadata.
+func Fetch(rawURL string, timeout time.Duration) (Meta, error) {
+	client := &http.Client{Timeout: timeout}
+	req, err := http.NewRequest("GET", rawURL, nil)
+	if err != nil {
+		return Meta{}, err
+	}
+	req.Header.Set("Accept", "text/html,application/xhtml+xml")
+	req.Header.Set("User-Agent", "Mozilla/5.0 (compatible; markdex)")
+	resp, err := client.Do(req)
+	if err != nil {
+		return Meta{}, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 400 {
+		return Meta{}, fmt.Errorf("http %d", resp.StatusCode)
+	}
+	ct := resp.Header.Get("Content-Type")
+	if mt, _, err := mime.ParseMediaType(ct); err == nil && mt != "text/html" && mt != "application/xhtml+xml" {
+		return Meta{}, fmt.Errorf("not an HTML page (%s)", mt)
+	}
+	body, err := charset.NewReader(io.LimitReader(resp.Body, MaxSize), ct)
+	if err != nil {
+		return Meta{}, err
+	}
+	m := Parse(body)
+	m.Canonical = absolute(resp.Request.URL, m.Canonical)
+	return m, nil
+}
+
+// Parse reads the metadata from an HTML document, stopping at <body>.
+func Parse(r io.Reader) Meta {
+	var m Meta
+	var title, ogTitle, desc, ogDesc, canonical, ogURL string
+	z := html.NewTokenizer(r)
+	inTitle := false
+loop:
+	for {
+		switch z.Next() {
+		case html.ErrorToken:
+			break loop
+		case html.StartTagToken, html.SelfClosingTagToken:
+			t := z.Token()
+			attr := func(name string) string {
+				for _, a := range t.Attr {
+					if strings.EqualFold(a.Key, name) {
+						return strings.TrimSpace(a.Val)
+					}
+				}
+				return ""
+			}
+			switch t.Data {
+			case "body":
+				break loop
+			case "title":
+				inTitle = title == ""
+			case "meta":
+				content := attr("content")
+				switch strings.ToLower(firstNonEmpty(attr("property"), attr("name"))) {
+				case "og:title":
+					ogTitle = content
+				case "description":
+					desc = content
+				case "og:description":
+					ogDesc = content
+				case "og:url":
+					ogURL = content
+				}
+			case "link":
+				for _, rel := range strings.Fields(strings.ToLower(attr("rel"))) {
+					if rel == "canonical" {
+						canonical = attr("href")
+					}
+				}
+			}
+		case html.TextToken:
+			if inTitle {
+				title += string(z.Text())
+			}
+		case html.EndTagToken:
+			if t := z.Token(); t.Data == "title" {
+				inTitle = false
+			} else if t.Data == "head" {
+				break loop
+			}
+		}
+	}
+	m.Title = clean(firstNonEmpty(ogTitle, title))
+	m.Description = clean(firstNonEmpty(desc, ogDesc))
+	m.Canonical = firstNonEmpty(canonical, ogURL)
+	return m
+}
+
+// clean collapses runs of whitespace.
+func clean(s string) string { return strings.Join(strings.Fields(s), " ") }
+
+func absolute(base *url.URL, ref string) string {
+	if ref == "" {
+		return ""
+	}
+	u, err := base.Parse(ref)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return ""
+	}
+	return u.String()
+}
+
+func firstNonEmpty(vals ...string) string {
+	for _, v := range vals {
+		if strings.TrimSpace(v) != "" {
+			return v
+		}
+	}
+	return ""
+}
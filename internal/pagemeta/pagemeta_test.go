@@ -0,0 +1,174 @@
+package pagemeta
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name string
+		html string
+		want Meta
+	}{
+		{
+			name: "og:title wins over title",
+			html: `<html><head><title>Page Title</title><meta property="og:title" content="OG Title"></head></html>`,
+			want: Meta{Title: "OG Title"},
+		},
+		{
+			name: "og:title before title",
+			html: `<head><meta property="og:title" content="OG Title"><title>Page Title</title></head>`,
+			want: Meta{Title: "OG Title"},
+		},
+		{
+			name: "title without og:title",
+			html: "<head><title>\n  Page &amp; \t Title\n</title></head>",
+			want: Meta{Title: "Page & Title"},
+		},
+		{
+			name: "empty og:title falls back",
+			html: `<head><meta property="og:title" content="  "><title>Page Title</title></head>`,
+			want: Meta{Title: "Page Title"},
+		},
+		{
+			name: "first title only",
+			html: `<head><title>First</title><svg><title>Icon</title></svg></head>`,
+			want: Meta{Title: "First"},
+		},
+		{
+			name: "description wins over og:description",
+			html: `<head><meta property="og:description" content="OG desc"><meta name="Description" content="Meta  desc"></head>`,
+			want: Meta{Description: "Meta desc"},
+		},
+		{
+			name: "og:description without description",
+			html: `<head><meta name="og:description" content="OG desc"></head>`,
+			want: Meta{Description: "OG desc"},
+		},
+		{
+			name: "canonical wins over og:url",
+			html: `<head><meta property="og:url" content="https://example.com/og"><link rel="Canonical" href="/canonical"></head>`,
+			want: Meta{Canonical: "/canonical"},
+		},
+		{
+			name: "og:url without canonical",
+			html: `<head><meta property="og:url" content="https://example.com/og"><link rel="stylesheet" href="/style.css"></head>`,
+			want: Meta{Canonical: "https://example.com/og"},
+		},
+		{
+			name: "stops at body",
+			html: `<html><head></head><body><title>Not this</title><meta name="description" content="nor this"></body></html>`,
+			want: Meta{},
+		},
+		{
+			name: "no head",
+			html: `<title>Bare</title><meta name=description content=bare>`,
+			want: Meta{Title: "Bare", Description: "bare"},
+		},
+		{
+			name: "empty",
+			html: ``,
+			want: Meta{},
+		},
+	}
+	for _, tt := range tests {
+		if got := Parse(strings.NewReader(tt.html)); got != tt.want {
+			t.Errorf("%s: Parse = %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestFetch(t *testing.T) {
+	latin1 := "<head><title>Caf\xe9 cr\xe8me</title><link rel=canonical href=/menu></head>"
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		want        Meta
+		err         string
+	}{
+		{
+			name:        "charset from the header",
+			contentType: "text/html; charset=iso-8859-1",
+			body:        latin1,
+			want:        Meta{Title: "Café crème", Canonical: "/menu"},
+		},
+		{
+			name:        "charset from a meta tag",
+			contentType: "text/html",
+			body:        `<meta charset="windows-1252">` + latin1,
+			want:        Meta{Title: "Café crème", Canonical: "/menu"},
+		},
+		{
+			name:        "utf-8",
+			contentType: "text/html; charset=utf-8",
+			body:        "<title>Café crème</title>",
+			want:        Meta{Title: "Café crème"},
+		},
+		{
+			name:        "xhtml",
+			contentType: "application/xhtml+xml",
+			body:        "<title>XHTML</title>",
+			want:        Meta{Title: "XHTML"},
+		},
+		{
+			name:        "not html",
+			contentType: "application/pdf",
+			body:        "%PDF-1.7",
+			err:         "not an HTML page (application/pdf)",
+		},
+		{
+			name:        "http error",
+			contentType: "text/html",
+			err:         "http 404",
+		},
+	}
+	for _, tt := range tests {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", tt.contentType)
+			if tt.err == "http 404" {
+				http.NotFound(w, r)
+				return
+			}
+			w.Write([]byte(tt.body))
+		}))
+		got, err := Fetch(srv.URL+"/page", 5*time.Second)
+		srv.Close()
+		if tt.err != "" {
+			if err == nil || err.Error() != tt.err {
+				t.Errorf("%s: Fetch error = %v, want %q", tt.name, err, tt.err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: Fetch: %v", tt.name, err)
+			continue
+		}
+		if tt.want.Canonical != "" {
+			tt.want.Canonical = srv.URL + tt.want.Canonical
+		}
+		if got != tt.want {
+			t.Errorf("%s: Fetch = %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestFetchSizeLimit(t *testing.T) {
+	// the title comes after MaxSize bytes of padding, so it is never read
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write([]byte("<head><meta name=description content=early><!--" + strings.Repeat("x", MaxSize) + "--><title>Late</title></head>"))
+	}))
+	defer srv.Close()
+	got, err := Fetch(srv.URL, 5*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (Meta{Description: "early"}); got != want {
+		t.Errorf("Fetch = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,105 @@
+package picker
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Item is one candidate. Fields are searched in order of importance (e.g. title, tags,
+// hash, URL): a match in an earlier field scores higher.
+type Item struct {
+	Label  string
+	Fields []string
+}
+
+type match struct {
+	index int
+	score int
+}
+
+// filter returns the indexes of items matching query, best first. Every space-separated
+// token must match one field as a case-insensitive subsequence; ties keep the input order.
+func filter(items []Item, query string) []int {
+	tokens := strings.Fields(strings.ToLower(query))
+	if len(tokens) == 0 {
+		out := make([]int, len(items))
+		for i := range out {
+			out[i] = i
+		}
+		return out
+	}
+	var ms []match
+	for i, it := range items {
+		total := 0
+		ok := true
+		for _, tok := range tokens {
+			best := -1
+			for f, field := range it.Fields {
+				if s := score(strings.ToLower(field), tok); s >= 0 {
+					// Earlier fields weigh more.
+					best = max(best, s+(len(it.Fields)-f)*4)
+				}
+			}
+			if best < 0 {
+				ok = false
+				break
+			}
+			total += best
+		}
+		if ok {
+			ms = append(ms, match{index: i, score: total})
+		}
+	}
+	sort.SliceStable(ms, func(a, b int) bool { return ms[a].score > ms[b].score })
+	out := make([]int, len(ms))
+	for i, m := range ms {
+		out[i] = m.index
+	}
+	return out
+}
+
+// score rates how well pattern matches s as a subsequence, or returns -1. Consecutive
+// characters, matches at word starts and an early first match score higher.
+func score(s, pattern string) int {
+	if pattern == "" {
+		return 0
+	}
+	if i := strings.Index(s, pattern); i >= 0 {
+		// A literal substring beats any scattered match.
+		bonus := 0
+		if i == 0 || !isWordRune(rune(s[i-1])) {
+			bonus = 8
+		}
+		return 16*len([]rune(pattern)) + bonus - min(i, 8)
+	}
+	p := []rune(pattern)
+	pi, total, run, first := 0, 0, 0, -1
+	prev := ' '
+	for i, r := range s {
+		if pi < len(p) && r == p[pi] {
+			if first < 0 {
+				first = i
+			}
+			pts := 1
+			if run > 0 {
+				pts += 4 * run
+			}
+			if !isWordRune(prev) {
+				pts += 6
+			}
+			total += pts
+			run++
+			pi++
+		} else {
+			run = 0
+		}
+		prev = r
+	}
+	if pi < len(p) {
+		return -1
+	}
+	return total - min(first, 8)
+}
+
+func isWordRune(r rune) bool { return unicode.IsLetter(r) || unicode.IsDigit(r) }
@@ -0,0 +1,63 @@
+package picker
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestScore(t *testing.T) {
+	for _, tt := range []struct {
+		s, pattern string
+		match      bool
+	}{
+		{"anything", "", true},
+		{"golang", "gg", true},
+		{"golang", "xyz", false},
+		{"golang", "lo", false}, // in order only
+		{"日本語のテキスト", "日テ", true},
+	} {
+		if got := score(tt.s, tt.pattern); (got >= 0) != tt.match {
+			t.Errorf("score(%q, %q) = %d, want a match: %v", tt.s, tt.pattern, got, tt.match)
+		}
+	}
+
+	// a pattern scores higher in the first string of each pair than in the second
+	for _, tt := range [][3]string{
+		{"go", "golang", "galore"},        // substring over scattered
+		{"lang", "go lang", "golang"},     // at a word start over inside a word
+		{"lang", "lang", "xxxxxxxx lang"}, // earlier over later
+		{"pm", "pick-me", "happy camper"}, // word starts over scattered
+		{"abc", "abcdef", "a-b-c"},        // consecutive over scattered
+	} {
+		if a, b := score(tt[1], tt[0]), score(tt[2], tt[0]); a <= b {
+			t.Errorf("%q: score in %q = %d, want more than in %q = %d", tt[0], tt[1], a, tt[2], b)
+		}
+	}
+}
+
+func TestFilter(t *testing.T) {
+	items := []Item{
+		{Label: "0", Fields: []string{"Go blog", "go,news", "https://go.dev/blog"}},
+		{Label: "1", Fields: []string{"Rust book", "rust", "https://doc.rust-lang.org/book"}},
+		{Label: "2", Fields: []string{"Weekly", "go", "https://golangweekly.com"}},
+		{Label: "3", Fields: []string{"Cooking", "", "https://example.com/recipes"}},
+	}
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{"", "[0 1 2 3]"},
+		{"   ", "[0 1 2 3]"},
+		{"GO", "[0 2 1]"}, // title match first; "rust-lang.org" has g…o too
+		{"go blog", "[0]"},
+		{"book rust", "[1]"},
+		{"recipes", "[3]"},
+		{"zzz", "[]"},
+		{"weekly go", "[2]"},
+	}
+	for _, tt := range tests {
+		if got := fmt.Sprint(filter(items, tt.query)); got != tt.want {
+			t.Errorf("filter(%q) = %s, want %s", tt.query, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,261 @@
+// Package picker is a small in-process fuzzy finder for the terminal, used by pick when
+// fzf is not available: type to filter, arrows to move, Tab to mark (multi-select), Enter
+// to accept and Esc to cancel. A preview of the highlighted item is shown beside the list.
+package picker
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/mattn/go-runewidth"
+	"golang.org/x/term"
+)
+
+// Options configures Run.
+type Options struct {
+	Prompt string
+	Query  string // initial query
+	Multi  bool
+	// Preview returns the lines describing items[i] within width columns; nil disables it.
+	Preview func(i, width int) []string
+}
+
+// ErrNoTerminal is returned when there is no terminal to draw on.
+var ErrNoTerminal = errors.New("picker: no terminal")
+
+type state struct {
+	items    []Item
+	opts     Options
+	query    []rune
+	matches  []int
+	cursor   int // position in matches
+	offset   int // first visible match
+	selected map[int]bool
+}
+
+// Run shows the picker and returns the indexes of the chosen items; it returns nil when
+// the user cancels.
+func Run(items []Item, opts Options) ([]int, error) {
+	in, out, err := openTerminal()
+	if err != nil {
+		return nil, err
+	}
+	defer in.Close()
+	if out != in {
+		defer out.Close()
+	}
+	old, err := term.MakeRaw(int(in.Fd()))
+	if err != nil {
+		return nil, fmt.Errorf("picker: %w", err)
+	}
+	defer term.Restore(int(in.Fd()), old)
+
+	w := bufio.NewWriter(out)
+	fmt.Fprint(w, "\x1b[?1049h\x1b[?25l") // alternate screen, hide cursor
+	defer func() {
+		fmt.Fprint(w, "\x1b[?25h\x1b[?1049l")
+		w.Flush()
+	}()
+
+	s := &state{items: items, opts: opts, query: []rune(opts.Query), selected: map[int]bool{}}
+	s.refilter()
+	buf := make([]byte, 64)
+	for {
+		width, height := size(out)
+		s.draw(w, width, height)
+		w.Flush()
+		n, err := in.Read(buf)
+		if err != nil {
+			return nil, err
+		}
+		done, chosen := s.key(buf[:n])
+		if done {
+			return chosen, nil
+		}
+	}
+}
+
+// openTerminal returns the controlling terminal, falling back to stdin/stdout.
+func openTerminal() (in, out *os.File, err error) {
+	if tty, err := os.OpenFile("/dev/tty", os.O_RDWR, 0); err == nil {
+		return tty, tty, nil
+	}
+	if term.IsTerminal(int(os.Stdin.Fd())) && term.IsTerminal(int(os.Stdout.Fd())) {
+		return os.Stdin, os.Stdout, nil
+	}
+	return nil, nil, ErrNoTerminal
+}
+
+func size(f *os.File) (w, h int) {
+	w, h, err := term.GetSize(int(f.Fd()))
+	if err != nil || w <= 0 || h <= 0 {
+		return 80, 24
+	}
+	return w, h
+}
+
+func (s *state) refilter() {
+	s.matches = filter(s.items, string(s.query))
+	s.cursor, s.offset = 0, 0
+}
+
+func (s *state) move(d int) {
+	s.cursor = max(0, min(s.cursor+d, len(s.matches)-1))
+}
+
+// key handles one read from the terminal and reports whether the picker is done.
+func (s *state) key(b []byte) (done bool, chosen []int) {
+	switch k := string(b); k {
+	case "\r":
+		// marked entries count even when the current query hides them
+		for i := range s.items {
+			if s.selected[i] {
+				chosen = append(chosen, i)
+			}
+		}
+		if len(chosen) == 0 {
+			if len(s.matches) == 0 {
+				return false, nil
+			}
+			chosen = []int{s.matches[s.cursor]}
+		}
+		return true, chosen
+	case "\x1b", "\x03", "\x07": // Esc, Ctrl-C, Ctrl-G
+		return true, nil
+	case "\x1b[A", "\x1bOA", "\x10", "\x0b": // Up, Ctrl-P, Ctrl-K
+		s.move(-1)
+	case "\x1b[B", "\x1bOB", "\x0e", "\x0a": // Down, Ctrl-N, Ctrl-J
+		s.move(1)
+	case "\x1b[5~":
+		s.move(-10)
+	case "\x1b[6~":
+		s.move(10)
+	case "\t":
+		if s.opts.Multi && len(s.matches) > 0 {
+			i := s.matches[s.cursor]
+			s.selected[i] = !s.selected[i]
+			s.move(1)
+		}
+	case "\x7f", "\x08": // Backspace
+		if len(s.query) > 0 {
+			s.query = s.query[:len(s.query)-1]
+			s.refilter()
+		}
+	case "\x15": // Ctrl-U
+		s.query = nil
+		s.refilter()
+	case "\x17": // Ctrl-W
+		q := strings.TrimRight(string(s.query), " ")
+		if i := strings.LastIndex(q, " "); i >= 0 {
+			s.query = []rune(q[:i+1])
+		} else {
+			s.query = nil
+		}
+		s.refilter()
+	default:
+		if strings.HasPrefix(k, "\x1b") {
+			return false, nil // other escape sequences
+		}
+		changed := false
+		for len(b) > 0 {
+			r, n := utf8.DecodeRune(b)
+			b = b[n:]
+			if r >= ' ' && r != utf8.RuneError {
+				s.query = append(s.query, r)
+				changed = true
+			}
+		}
+		if changed {
+			s.refilter()
+		}
+	}
+	return false, nil
+}
+
+func (s *state) draw(w *bufio.Writer, width, height int) {
+	listW := width
+	previewW := 0
+	if s.opts.Preview != nil && width >= 60 {
+		listW = width * 11 / 20
+		previewW = width - listW - 3
+	}
+	rows := max(height-2, 1)
+	if s.cursor < s.offset {
+		s.offset = s.cursor
+	}
+	if s.cursor >= s.offset+rows {
+		s.offset = s.cursor - rows + 1
+	}
+	var preview []string
+	if previewW > 0 && len(s.matches) > 0 {
+		preview = s.opts.Preview(s.matches[s.cursor], previewW)
+	}
+
+	fmt.Fprint(w, "\x1b[H")
+	prompt := s.opts.Prompt + string(s.query)
+	fmt.Fprintf(w, "\x1b[1m%s\x1b[0m\x1b[7m \x1b[0m\x1b[K\r\n", fit(prompt, width-1))
+	info := fmt.Sprintf("  %d/%d", len(s.matches), len(s.items))
+	if n := countTrue(s.selected); s.opts.Multi && n > 0 {
+		info += fmt.Sprintf(" (%d selected)", n)
+	}
+	fmt.Fprintf(w, "\x1b[2m%s\x1b[0m\x1b[K\r\n", fit(info, width))
+	for r := 0; r < rows; r++ {
+		line := ""
+		if i := s.offset + r; i < len(s.matches) {
+			idx := s.matches[i]
+			mark := "  "
+			if s.selected[idx] {
+				mark = "* "
+			}
+			text := pad(fit(mark+s.items[idx].Label, listW-1), listW-1)
+			if i == s.cursor {
+				line = "\x1b[7m>" + text + "\x1b[0m"
+			} else {
+				line = " " + text
+			}
+		} else {
+			line = strings.Repeat(" ", listW)
+		}
+		if previewW > 0 {
+			p := ""
+			if r < len(preview) {
+				p = fit(preview[r], previewW)
+			}
+			line += " \x1b[2m│\x1b[0m " + p
+		}
+		fmt.Fprint(w, line, "\x1b[K")
+		if r < rows-1 {
+			fmt.Fprint(w, "\r\n")
+		}
+	}
+}
+
+func countTrue(m map[int]bool) int {
+	n := 0
+	for _, v := range m {
+		if v {
+			n++
+		}
+	}
+	return n
+}
+
+// fit truncates s to n columns.
+func fit(s string, n int) string {
+	if n <= 0 {
+		return ""
+	}
+	return runewidth.Truncate(s, n, "…")
+}
+
+// pad fills s with spaces to n columns.
+func pad(s string, n int) string {
+	if w := runewidth.StringWidth(s); w < n {
+		return s + strings.Repeat(" ", n-w)
+	}
+	return s
+}
@@ -0,0 +1,54 @@
+package picker
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestKey(t *testing.T) {
+	items := []Item{
+		{Label: "go", Fields: []string{"Go blog"}},
+		{Label: "rust", Fields: []string{"Rust book"}},
+		{Label: "zig", Fields: []string{"Zig guide"}},
+	}
+	tests := []struct {
+		name  string
+		multi bool
+		keys  []string
+		done  bool
+		want  string // chosen indexes
+		query string
+	}{
+		{name: "enter picks the first", keys: []string{"\r"}, done: true, want: "[0]"},
+		{name: "down then enter", keys: []string{"\x1b[B", "\x0e", "\r"}, done: true, want: "[2]"},
+		{name: "moves stop at the ends", keys: []string{"\x1b[A", "\x1b[6~", "\x1b[A", "\r"}, done: true, want: "[1]"},
+		{name: "typing filters", keys: []string{"ru", "s", "\r"}, done: true, want: "[1]"},
+		{name: "utf-8 and control bytes", keys: []string{"é\x01x"}, query: "éx"},
+		{name: "backspace", keys: []string{"zx", "\x7f", "\r"}, done: true, want: "[2]"},
+		{name: "ctrl-w deletes a word", keys: []string{"go bl", "\x17"}, query: "go "},
+		{name: "ctrl-u clears", keys: []string{"go bl", "\x15"}, query: ""},
+		{name: "no match: enter does nothing", keys: []string{"qq", "\r"}, query: "qq"},
+		{name: "escape cancels", keys: []string{"\x1b"}, done: true, want: "[]"},
+		{name: "other escape sequences are ignored", keys: []string{"\x1b[1;5C", "\r"}, done: true, want: "[0]"},
+		{name: "tab without multi", keys: []string{"\t", "\r"}, done: true, want: "[0]"},
+		{name: "multi", multi: true, keys: []string{"\t", "\x1b[B", "\t", "\r"}, done: true, want: "[0 2]"},
+		{name: "marks survive the query", multi: true, keys: []string{"\t", "zig", "\t", "\r"}, done: true, want: "[0 2]"},
+	}
+	for _, tt := range tests {
+		s := &state{items: items, opts: Options{Multi: tt.multi}, selected: map[int]bool{}}
+		s.refilter()
+		var done bool
+		var chosen []int
+		for _, k := range tt.keys {
+			if done, chosen = s.key([]byte(k)); done {
+				break
+			}
+		}
+		if done != tt.done || (done && fmt.Sprint(chosen) != tt.want) {
+			t.Errorf("%s: done %v, chosen %v; want %v, %s", tt.name, done, chosen, tt.done, tt.want)
+		}
+		if !done && string(s.query) != tt.query {
+			t.Errorf("%s: query %q, want %q", tt.name, string(s.query), tt.query)
+		}
+	}
+}
@@ -0,0 +1,299 @@
+// Package query implements the small search language accepted as the positional query of
+// list, pick and open, e.g.
+//
+//	tag:go -tag:old site:github.com usage>5 in:inbox.md "exact phrase"
+//
+// Terms are separated by spaces and must all match; a leading '-' negates a term.
+// Supported terms:
+//
+//	word, "a phrase"   title or description contains the text (case-insensitive)
+//	tag:go             has the tag (tag:go,rust: has any of them)
+//	site:github.com    URL host is github.com or a subdomain of it (alias: host:)
+//	in:inbox.md        source file is inbox.md (also matches notes/inbox.md)
+//	section:Go         section equals the value
+//	title:, desc:, url: the field contains the value
+//	hash:abc           hash starts with the value
+//	usage>5            usage compared with >, >=, <, <=, = or :
+package query
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/amaterasu/markdex-cli/internal/api"
+)
+
+// Query is a parsed query; the zero value matches everything.
+type Query struct {
+	terms []term
+}
+
+type term struct {
+	negate bool
+	match  func(b api.Bookmark) bool
+}
+
+// Error describes a syntax error and where in the input it occurred.
+type Error struct {
+	Input string
+	Pos   int // byte offset into Input
+	Msg   string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("query: %s at column %d\n  %s\n  %s^", e.Msg, e.Pos+1, e.Input, strings.Repeat(" ", len([]rune(e.Input[:e.Pos]))))
+}
+
+// Empty reports whether the query has no terms.
+func (q Query) Empty() bool { return len(q.terms) == 0 }
+
+// Match reports whether b satisfies every term.
+func (q Query) Match(b api.Bookmark) bool {
+	for _, t := range q.terms {
+		if t.match(b) == t.negate {
+			return false
+		}
+	}
+	return true
+}
+
+// Apply returns the bookmarks matching q.
+func (q Query) Apply(items []api.Bookmark) []api.Bookmark {
+	if q.Empty() {
+		return items
+	}
+	out := items[:0:0]
+	for _, b := range items {
+		if q.Match(b) {
+			out = append(out, b)
+		}
+	}
+	return out
+}
+
+// Parse parses a query string.
+func Parse(input string) (Query, error) {
+	p := &parser{in: input}
+	var q Query
+	for {
+		p.skipSpace()
+		if p.pos >= len(p.in) {
+			return q, nil
+		}
+		t, err := p.term()
+		if err != nil {
+			return Query{}, err
+		}
+		q.terms = append(q.terms, t)
+	}
+}
+
+type parser struct {
+	in  string
+	pos int
+}
+
+func (p *parser) errorf(pos int, format string, a ...any) error {
+	return &Error{Input: p.in, Pos: pos, Msg: fmt.Sprintf(format, a...)}
+}
+
+func (p *parser) skipSpace() {
+	for p.pos < len(p.in) && (p.in[p.pos] == ' ' || p.in[p.pos] == '\t') {
+		p.pos++
+	}
+}
+
+func (p *parser) atEnd() bool {
+	return p.pos >= len(p.in) || p.in[p.pos] == ' ' || p.in[p.pos] == '\t'
+}
+
+// quoted reads a "..." string starting at the opening quote.
+func (p *parser) quoted() (string, error) {
+	start := p.pos
+	p.pos++ // opening quote
+	end := strings.IndexByte(p.in[p.pos:], '"')
+	if end < 0 {
+		return "", p.errorf(start, "unterminated quote")
+	}
+	s := p.in[p.pos : p.pos+end]
+	p.pos += end + 1
+	if !p.atEnd() {
+		return "", p.errorf(p.pos, "expected space after closing quote")
+	}
+	return s, nil
+}
+
+// word reads up to the next space.
+func (p *parser) word() string {
+	start := p.pos
+	for !p.atEnd() {
+		p.pos++
+	}
+	return p.in[start:p.pos]
+}
+
+func (p *parser) term() (term, error) {
+	var t term
+	start := p.pos
+	if p.in[p.pos] == '-' {
+		t.negate = true
+		p.pos++
+		if p.atEnd() {
+			return t, p.errorf(start, "'-' must be followed by a term")
+		}
+	}
+	if p.in[p.pos] == '"' {
+		s, err := p.quoted()
+		if err != nil {
+			return t, err
+		}
+		if s == "" {
+			return t, p.errorf(start, "empty phrase")
+		}
+		t.match = textMatch(s)
+		return t, nil
+	}
+
+	fieldStart := p.pos
+	// A field name is letters followed by ':' or a comparison operator.
+	i := p.pos
+	for i < len(p.in) && isLetter(p.in[i]) {
+		i++
+	}
+	if i == p.pos || i >= len(p.in) || !strings.ContainsRune(":<>=", rune(p.in[i])) {
+		w := p.word()
+		t.match = textMatch(w)
+		return t, nil
+	}
+	field := strings.ToLower(p.in[p.pos:i])
+	p.pos = i
+
+	if field == "usage" {
+		m, err := p.comparison(fieldStart)
+		t.match = m
+		return t, err
+	}
+	if p.in[p.pos] != ':' {
+		return t, p.errorf(p.pos, "field %q only supports ':' (comparisons work with usage)", field)
+	}
+	p.pos++
+	valueStart := p.pos
+	var value string
+	if p.pos < len(p.in) && p.in[p.pos] == '"' {
+		v, err := p.quoted()
+		if err != nil {
+			return t, err
+		}
+		value = v
+	} else {
+		value = p.word()
+	}
+	if value == "" {
+		return t, p.errorf(valueStart, "missing value for %s:", field)
+	}
+	m, ok := fieldMatch(field, value)
+	if !ok {
+		return t, p.errorf(fieldStart, "unknown field %q (quote the term to search for it as text)", field)
+	}
+	t.match = m
+	return t, nil
+}
+
+// comparison parses the operator and number after "usage".
+func (p *parser) comparison(fieldStart int) (func(api.Bookmark) bool, error) {
+	opStart := p.pos
+	op := ""
+	for _, cand := range []string{">=", "<=", ">", "<", "=", ":"} {
+		if strings.HasPrefix(p.in[p.pos:], cand) {
+			op = cand
+			break
+		}
+	}
+	if op == "" {
+		return nil, p.errorf(opStart, "expected comparison operator after usage")
+	}
+	p.pos += len(op)
+	numStart := p.pos
+	raw := p.word()
+	if raw == "" {
+		return nil, p.errorf(numStart, "missing number after usage%s", op)
+	}
+	n, err := strconv.Atoi(raw)
+	if err != nil {
+		return nil, p.errorf(numStart, "%q is not a number", raw)
+	}
+	return func(b api.Bookmark) bool {
+		switch op {
+		case ">":
+			return b.Usage > n
+		case ">=":
+			return b.Usage >= n
+		case "<":
+			return b.Usage < n
+		case "<=":
+			return b.Usage <= n
+		default:
+			return b.Usage == n
+		}
+	}, nil
+}
+
+func fieldMatch(field, value string) (func(api.Bookmark) bool, bool) {
+	lv := strings.ToLower(value)
+	switch field {
+	case "tag", "tags":
+		wants := strings.Split(lv, ",")
+		return func(b api.Bookmark) bool {
+			for _, t := range b.Tags {
+				for _, w := range wants {
+					if strings.EqualFold(t, w) {
+						return true
+					}
+				}
+			}
+			return false
+		}, true
+	case "site", "host":
+		lv = strings.TrimPrefix(lv, "www.")
+		return func(b api.Bookmark) bool {
+			h := strings.TrimPrefix(strings.ToLower(hostOf(b.URL)), "www.")
+			return h == lv || strings.HasSuffix(h, "."+lv)
+		}, true
+	case "in", "file":
+		return func(b api.Bookmark) bool {
+			f := strings.ToLower(b.SourceFile)
+			return f == lv || strings.HasSuffix(f, "/"+lv)
+		}, true
+	case "section":
+		return func(b api.Bookmark) bool { return strings.EqualFold(b.Section, value) }, true
+	case "title":
+		return func(b api.Bookmark) bool { return containsFold(b.Title, lv) }, true
+	case "desc", "description":
+		return func(b api.Bookmark) bool { return containsFold(b.Description, lv) }, true
+	case "url":
+		return func(b api.Bookmark) bool { return containsFold(b.URL, lv) }, true
+	case "hash":
+		return func(b api.Bookmark) bool { return strings.HasPrefix(strings.ToLower(b.Hash), lv) }, true
+	}
+	return nil, false
+}
+
+func textMatch(s string) func(api.Bookmark) bool {
+	ls := strings.ToLower(s)
+	return func(b api.Bookmark) bool { return containsFold(b.Title, ls) || containsFold(b.Description, ls) }
+}
+
+// containsFold reports whether s contains the lowercase substring sub, ignoring case.
+func containsFold(s, sub string) bool { return strings.Contains(strings.ToLower(s), sub) }
+
+func hostOf(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return ""
+	}
+	return u.Hostname()
+}
+
+func isLetter(c byte) bool { return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' }
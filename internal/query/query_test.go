@@ -0,0 +1,118 @@
+package query
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/amaterasu/markdex-cli/internal/api"
+)
+
+var items = []api.Bookmark{
+	{Hash: "abc123", Title: "Go blog", Description: "news about go", URL: "https://go.dev/blog", Tags: []string{"go"}, SourceFile: "notes/inbox.md", Usage: 7},
+	{Hash: "def456", Title: "Rust book", Description: "learn rust", URL: "https://doc.rust-lang.org/book", Tags: []string{"rust", "old"}, Section: "Rust", Usage: 2},
+	{Hash: "0f0f0f", Title: "Pull requests", Description: "code-review queue", URL: "https://www.github.com/pulls", Tags: []string{"work"}, SourceFile: "work.md"},
+}
+
+func hashes(q Query) string {
+	var out []string
+	for _, b := range q.Apply(items) {
+		out = append(out, b.Hash)
+	}
+	return strings.Join(out, ",")
+}
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		query, want string
+	}{
+		{"", "abc123,def456,0f0f0f"},
+		{"go", "abc123"},
+		{"GO", "abc123"},
+		{`"rust book"`, "def456"},
+		{"tag:go,rust", "abc123,def456"},
+		{"-tag:old", "abc123,0f0f0f"},
+		{`-"learn rust"`, "abc123,0f0f0f"},
+		{"site:github.com", "0f0f0f"},
+		{"host:rust-lang.org", "def456"},
+		{"in:inbox.md", "abc123"},
+		{"section:rust", "def456"},
+		{`title:"rust b"`, "def456"},
+		{"desc:queue url:pulls", "0f0f0f"},
+		{"hash:DEF", "def456"},
+		{"usage>2", "abc123"},
+		{"usage>=2", "abc123,def456"},
+		{"usage<2", "0f0f0f"},
+		{"usage<=2", "def456,0f0f0f"},
+		{"usage=7", "abc123"},
+		{"usage:0", "0f0f0f"},
+		{"  tag:go \t usage>5  ", "abc123"},
+		// a second '-' is part of the negated text: "--" excludes anything containing "-"
+		{"--", "abc123,def456"},
+		{"-code-review", "abc123,def456"},
+		// words that only look like fields are text
+		{"c++", ""},
+		{"1:2", ""},
+		{"usage", ""},
+		{`"tag:go"`, ""},
+	}
+	for _, tt := range tests {
+		q, err := Parse(tt.query)
+		if err != nil {
+			t.Errorf("Parse(%q): %v", tt.query, err)
+			continue
+		}
+		if got := hashes(q); got != tt.want {
+			t.Errorf("Parse(%q) matched %q, want %q", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		query string
+		pos   int
+		msg   string
+	}{
+		{"-", 0, "'-' must be followed by a term"},
+		{"go - rust", 3, "'-' must be followed by a term"},
+		{`"open`, 0, "unterminated quote"},
+		{`go "open phrase`, 3, "unterminated quote"},
+		{`title:"open`, 6, "unterminated quote"},
+		{`"a"b`, 3, "expected space after closing quote"},
+		{`""`, 0, "empty phrase"},
+		{`-""`, 0, "empty phrase"},
+		{"tag:", 4, "missing value for tag:"},
+		{"tag>go", 3, `field "tag" only supports ':' (comparisons work with usage)`},
+		{"usage>", 6, "missing number after usage>"},
+		{"usage>=x", 7, `"x" is not a number`},
+		{"go colour:red", 3, `unknown field "colour" (quote the term to search for it as text)`},
+	}
+	for _, tt := range tests {
+		_, err := Parse(tt.query)
+		var e *Error
+		if !errors.As(err, &e) {
+			t.Errorf("Parse(%q) = %v, want a syntax error", tt.query, err)
+			continue
+		}
+		if e.Pos != tt.pos || e.Msg != tt.msg {
+			t.Errorf("Parse(%q): %q at %d, want %q at %d", tt.query, e.Msg, e.Pos, tt.msg, tt.pos)
+		}
+	}
+}
+
+func TestErrorCaret(t *testing.T) {
+	tests := []struct {
+		query, want string
+	}{
+		{"usage>", "query: missing number after usage> at column 7\n  usage>\n        ^"},
+		// the caret counts runes, not bytes, so it lines up under multi-byte text
+		{`café "open`, "query: unterminated quote at column 7\n  café \"open\n       ^"},
+	}
+	for _, tt := range tests {
+		_, err := Parse(tt.query)
+		if err == nil || err.Error() != tt.want {
+			t.Errorf("Parse(%q) error:\n%v\nwant:\n%s", tt.query, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,165 @@
+// Package queue keeps usage events that could not be delivered to the server, so they
+// can be sent later instead of being lost.
+package queue
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/amaterasu/markdex-cli/internal/util"
+)
+
+// Event is one usage report waiting to be sent.
+type Event struct {
+	Base   string    `json:"base"` // API base the event belongs to
+	Hash   string    `json:"hash"`
+	UserID string    `json:"user_id"`
+	Time   time.Time `json:"time"`
+}
+
+// Path returns the queue file: usage-queue.jsonl in the state directory.
+func Path() string { return filepath.Join(util.StateDir(), "usage-queue.jsonl") }
+
+// Add appends events to the queue.
+func Add(events ...Event) error {
+	if len(events) == 0 {
+		return nil
+	}
+	p := Path()
+	if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(p, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	w := bufio.NewWriter(f)
+	for _, e := range events {
+		if e.Time.IsZero() {
+			e.Time = time.Now()
+		}
+		b, _ := json.Marshal(e)
+		w.Write(append(b, '\n'))
+	}
+	return w.Flush()
+}
+
+// Load returns the queued events, oldest first.
+func Load() ([]Event, error) { return read(Path()) }
+
+func read(p string) ([]Event, error) {
+	f, err := os.Open(p)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var out []Event
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		var e Event
+		if json.Unmarshal(sc.Bytes(), &e) == nil && e.Hash != "" {
+			out = append(out, e)
+		}
+	}
+	return out, sc.Err()
+}
+
+// staleClaim is how long a claimed queue may stay with a running process before another
+// flush takes it over.
+const staleClaim = 10 * time.Minute
+
+// Flush hands the events queued for base to send, which returns how many of them (from
+// the start) were delivered; the rest stay queued. The queue is moved aside to
+// <path>.<pid> while sending so that concurrent runs do not deliver the same events twice;
+// such files left by runs that died are taken over.
+func Flush(base string, send func([]Event) (int, error)) (sent, left int, err error) {
+	p := Path()
+	own := fmt.Sprintf("%s.%d", p, os.Getpid())
+	claims := adoptOrphans(p, own)
+	if err := os.Rename(p, own); err == nil {
+		now := time.Now()
+		os.Chtimes(own, now, now) // the claim's age tells others it is being sent
+		claims = append(claims, own)
+	} else if !os.IsNotExist(err) {
+		return 0, 0, err
+	}
+	var events []Event
+	for _, c := range claims {
+		ev, err := read(c)
+		if err != nil {
+			// the claims stay on disk and are taken over once this process is gone
+			return 0, 0, err
+		}
+		events = append(events, ev...)
+	}
+	var mine, others []Event
+	for _, e := range events {
+		if e.Base == base {
+			mine = append(mine, e)
+		} else {
+			others = append(others, e)
+		}
+	}
+	if len(mine) > 0 {
+		sent, err = send(mine)
+	}
+	keep := append(others, mine[sent:]...)
+	if aerr := Add(keep...); aerr != nil {
+		// leave the claimed copies on disk rather than deleting unsent events
+		return sent, len(keep), aerr
+	}
+	for _, c := range claims {
+		os.Remove(c)
+	}
+	return sent, len(mine) - sent, err
+}
+
+// adoptOrphans renames the claimed queues of flushes that did not finish (their process
+// is gone, or they are older than staleClaim) to names of this process, returning them.
+func adoptOrphans(p, own string) []string {
+	matches, _ := filepath.Glob(p + ".*")
+	var out []string
+	for i, m := range matches {
+		pidText, _, _ := strings.Cut(strings.TrimPrefix(m, p+"."), ".")
+		pid, err := strconv.Atoi(pidText)
+		if err != nil || pid == os.Getpid() {
+			continue
+		}
+		info, err := os.Stat(m)
+		if err != nil || (processAlive(pid) && time.Since(info.ModTime()) < staleClaim) {
+			continue
+		}
+		name := fmt.Sprintf("%s.%d", own, i)
+		if os.Rename(m, name) == nil {
+			out = append(out, name)
+		}
+	}
+	return out
+}
+
+// processAlive reports whether a process with this pid runs. On Windows it is assumed to,
+// leaving the claim's age to decide.
+func processAlive(pid int) bool {
+	if runtime.GOOS == "windows" {
+		return true
+	}
+	proc, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	err = proc.Signal(syscall.Signal(0))
+	return err == nil || errors.Is(err, syscall.EPERM)
+}
@@ -0,0 +1,136 @@
+package queue
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func hashes(events []Event) string {
+	var out []string
+	for _, e := range events {
+		out = append(out, e.Hash)
+	}
+	return strings.Join(out, ",")
+}
+
+func TestFlush(t *testing.T) {
+	tests := []struct {
+		name      string
+		queued    []Event
+		delivered int // how many events send accepts
+		sendErr   error
+		sent      string
+		left      string // queue afterwards
+	}{
+		{name: "empty"},
+		{
+			name:      "all sent",
+			queued:    []Event{{Base: "a", Hash: "1"}, {Base: "a", Hash: "2"}},
+			delivered: 2,
+			sent:      "1,2",
+		},
+		{
+			name:      "other servers stay",
+			queued:    []Event{{Base: "a", Hash: "1"}, {Base: "b", Hash: "2"}, {Base: "a", Hash: "3"}},
+			delivered: 2,
+			sent:      "1,3",
+			left:      "2",
+		},
+		{
+			name:      "partly sent",
+			queued:    []Event{{Base: "a", Hash: "1"}, {Base: "a", Hash: "2"}, {Base: "a", Hash: "3"}},
+			delivered: 1,
+			sendErr:   errors.New("offline"),
+			sent:      "1,2,3",
+			left:      "2,3",
+		},
+	}
+	for _, tt := range tests {
+		t.Setenv("XDG_STATE_HOME", t.TempDir())
+		if err := Add(tt.queued...); err != nil {
+			t.Fatal(err)
+		}
+		var got []Event
+		sent, left, err := Flush("a", func(events []Event) (int, error) {
+			got = events
+			return tt.delivered, tt.sendErr
+		})
+		if err != tt.sendErr || sent != tt.delivered {
+			t.Errorf("%s: Flush = %d sent, %v; want %d, %v", tt.name, sent, err, tt.delivered, tt.sendErr)
+		}
+		if hashes(got) != tt.sent {
+			t.Errorf("%s: send got %q, want %q", tt.name, hashes(got), tt.sent)
+		}
+		rest, _ := Load()
+		if hashes(rest) != tt.left {
+			t.Errorf("%s: queue is %q, want %q", tt.name, hashes(rest), tt.left)
+		}
+		mine := 0
+		for _, e := range tt.queued {
+			if e.Base == "a" {
+				mine++
+			}
+		}
+		if left != mine-tt.delivered {
+			t.Errorf("%s: left = %d, want %d", tt.name, left, mine-tt.delivered)
+		}
+	}
+}
+
+func TestFlushAdoptsOrphans(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("claims are only taken over by age on Windows")
+	}
+	dead := exec.Command("true")
+	if err := dead.Run(); err != nil {
+		t.Skip("cannot start a process:", err)
+	}
+	deadPID, alivePID := dead.Process.Pid, os.Getppid()
+	old := time.Now().Add(-2 * staleClaim)
+
+	tests := []struct {
+		name  string
+		pid   int
+		mtime time.Time
+		taken bool
+	}{
+		{"process gone", deadPID, time.Now(), true},
+		{"adopted by a process that is gone", deadPID, time.Now(), true}, // <path>.<pid>.<i>
+		{"running", alivePID, time.Now(), false},
+		{"running but stale", alivePID, old, true},
+	}
+	for i, tt := range tests {
+		t.Setenv("XDG_STATE_HOME", t.TempDir())
+		Add(Event{Base: "a", Hash: "queued"})
+		claim := fmt.Sprintf("%s.%d", Path(), tt.pid)
+		if i == 1 {
+			claim += ".0"
+		}
+		os.Rename(Path(), claim)
+		os.Chtimes(claim, tt.mtime, tt.mtime)
+		Add(Event{Base: "a", Hash: "new"})
+
+		var got []Event
+		Flush("a", func(events []Event) (int, error) {
+			got = events
+			return len(events), nil
+		})
+		want := "new"
+		if tt.taken {
+			want = "queued,new"
+		}
+		if hashes(got) != want {
+			t.Errorf("%s: sent %q, want %q", tt.name, hashes(got), want)
+		}
+		_, err := os.Stat(claim)
+		if tt.taken != os.IsNotExist(err) {
+			t.Errorf("%s: claim file still there: %v", tt.name, !os.IsNotExist(err))
+		}
+	}
+}
@@ -0,0 +1,165 @@
+// Package urlnorm rewrites URLs into a canonical form so that addresses of the same page
+// (differing only in tracking parameters, host case, default ports and the like) compare equal.
+package urlnorm
+
+import (
+	"fmt"
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+)
+
+// RuleNames lists the rules understood by ParseRules.
+var RuleNames = []string{"tracking", "host", "port", "query", "fragment", "slash", "https"}
+
+// DefaultRules is the rule list used unless configured otherwise.
+const DefaultRules = "tracking,host,port,query,fragment"
+
+// TrackingParams are the query parameters removed by the tracking rule; * matches any
+// characters.
+var TrackingParams = []string{
+	"utm_*", "fbclid", "gclid", "dclid", "gbraid", "wbraid", "msclkid", "yclid", "igshid",
+	"mc_cid", "mc_eid", "_ga", "_gl", "_hsenc", "_hsmi", "mkt_tok", "ref_src", "vero_id",
+}
+
+// Rules selects the rewrites Normalize applies.
+type Rules struct {
+	Tracking bool // remove tracking query parameters (TrackingParams plus Extra)
+	Host     bool // lowercase the host
+	Port     bool // drop :80 for http and :443 for https
+	Query    bool // sort query parameters by name
+	Fragment bool // drop the #fragment, except #/ and #! routes of web apps
+	Slash    bool // drop a trailing slash from the path
+	HTTPS    bool // use https instead of http
+
+	Extra []string // more tracking parameters
+}
+
+// ParseRules reads a comma-separated list of rule names and extra tracking parameters.
+func ParseRules(list, extra string) (Rules, error) {
+	var r Rules
+	for _, name := range splitList(list) {
+		switch strings.ToLower(name) {
+		case "tracking":
+			r.Tracking = true
+		case "host":
+			r.Host = true
+		case "port":
+			r.Port = true
+		case "query":
+			r.Query = true
+		case "fragment":
+			r.Fragment = true
+		case "slash":
+			r.Slash = true
+		case "https":
+			r.HTTPS = true
+		default:
+			return Rules{}, fmt.Errorf("unknown URL rule %q (valid: %s)", name, strings.Join(RuleNames, ", "))
+		}
+	}
+	r.Extra = splitList(extra)
+	return r, nil
+}
+
+func splitList(s string) []string {
+	var out []string
+	for _, f := range strings.Split(s, ",") {
+		if f = strings.TrimSpace(f); f != "" {
+			out = append(out, f)
+		}
+	}
+	return out
+}
+
+// Normalize applies the rules to raw. Only absolute http(s) URLs are changed; anything
+// else is returned as given.
+func (r Rules) Normalize(raw string) string {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil || u.Host == "" || (!strings.EqualFold(u.Scheme, "http") && !strings.EqualFold(u.Scheme, "https")) {
+		return raw
+	}
+	u.Scheme = strings.ToLower(u.Scheme)
+	if r.HTTPS && u.Scheme == "http" {
+		if u.Port() == "80" {
+			u.Host = u.Hostname()
+		}
+		u.Scheme = "https"
+	}
+	if r.Host {
+		u.Host = strings.ToLower(u.Host)
+	}
+	if r.Port && ((u.Scheme == "http" && u.Port() == "80") || (u.Scheme == "https" && u.Port() == "443")) {
+		u.Host = strings.TrimSuffix(u.Host, ":"+u.Port())
+	}
+	if r.Tracking || r.Query {
+		u.RawQuery = r.query(u.RawQuery)
+		u.ForceQuery = false
+	}
+	if r.Fragment && !strings.HasPrefix(u.Fragment, "/") && !strings.HasPrefix(u.Fragment, "!") {
+		u.Fragment, u.RawFragment = "", ""
+	}
+	if r.Slash && len(u.Path) > 1 {
+		u.Path = strings.TrimRight(u.Path, "/")
+		u.RawPath = strings.TrimRight(u.RawPath, "/")
+	}
+	return u.String()
+}
+
+// query filters and sorts the raw query string, keeping each parameter's original encoding.
+func (r Rules) query(raw string) string {
+	if raw == "" {
+		return ""
+	}
+	var keep []string
+	for _, pair := range strings.Split(raw, "&") {
+		if pair == "" {
+			continue
+		}
+		if r.Tracking && r.tracking(paramName(pair)) {
+			continue
+		}
+		keep = append(keep, pair)
+	}
+	if r.Query {
+		sort.SliceStable(keep, func(i, j int) bool { return paramName(keep[i]) < paramName(keep[j]) })
+	}
+	return strings.Join(keep, "&")
+}
+
+func paramName(pair string) string {
+	name, _, _ := strings.Cut(pair, "=")
+	if n, err := url.QueryUnescape(name); err == nil {
+		return n
+	}
+	return name
+}
+
+func (r Rules) tracking(name string) bool {
+	name = strings.ToLower(name)
+	for _, p := range append(TrackingParams, r.Extra...) {
+		if ok, _ := path.Match(strings.ToLower(p), name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Key returns what identifies the page behind raw when looking for duplicates: the URL
+// with every rule applied (keeping r's tracking parameters), ignoring http vs https and a
+// trailing slash.
+func (r Rules) Key(raw string) string {
+	all := Rules{Tracking: true, Host: true, Port: true, Query: true, Fragment: true, Slash: true, Extra: r.Extra}
+	k := all.Normalize(raw)
+	if u, err := url.Parse(k); err == nil && u.Host != "" && u.Path == "" {
+		u.Path = "/" // example.com?q is example.com/?q
+		k = u.String()
+	}
+	if rest, ok := strings.CutPrefix(k, "https://"); ok {
+		k = "//" + rest
+	} else if rest, ok := strings.CutPrefix(k, "http://"); ok {
+		k = "//" + rest
+	}
+	return strings.TrimSuffix(k, "/")
+}
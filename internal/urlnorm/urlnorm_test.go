@@ -0,0 +1,115 @@
+package urlnorm
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNormalize(t *testing.T) {
+	def, err := ParseRules(DefaultRules, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name  string
+		rules Rules
+		in    string
+		want  string
+	}{
+		{"tracking params", def, "https://example.com/a?utm_source=x&id=3&utm_medium=y&fbclid=z", "https://example.com/a?id=3"},
+		{"tracking case-insensitive", def, "https://example.com/a?UTM_Source=x&GCLID=1&q=go", "https://example.com/a?q=go"},
+		{"only tracking params", def, "https://example.com/a?utm_source=x&_ga=1", "https://example.com/a"},
+		{"empty query", def, "https://example.com/a?", "https://example.com/a"},
+		{"empty pairs", def, "https://example.com/a?b=1&&a=2&", "https://example.com/a?a=2&b=1"},
+		{"sorted keeps encoding and order of repeats", def, "https://example.com/s?q=a%20b&b=2&a=1&b=1", "https://example.com/s?a=1&b=2&b=1&q=a%20b"},
+		{"encoded tracking name", def, "https://example.com/?utm%5Fsource=x&p=1", "https://example.com/?p=1"},
+		{"host lowercased", def, "https://Example.COM/Path", "https://example.com/Path"},
+		{"scheme lowercased", def, "HTTPS://example.com/", "https://example.com/"},
+		{"default https port", def, "https://example.com:443/a", "https://example.com/a"},
+		{"default http port", def, "http://example.com:80/a", "http://example.com/a"},
+		{"other port kept", def, "https://example.com:8443/a", "https://example.com:8443/a"},
+		{"https on :80 kept", def, "https://example.com:80/a", "https://example.com:80/a"},
+		{"fragment dropped", def, "https://example.com/a#section", "https://example.com/a"},
+		{"hash route kept", def, "https://app.example.com/#/inbox", "https://app.example.com/#/inbox"},
+		{"hashbang kept", def, "https://app.example.com/#!/inbox", "https://app.example.com/#!/inbox"},
+		{"slash kept by default", def, "https://example.com/a/", "https://example.com/a/"},
+		{"slash", Rules{Slash: true}, "https://example.com/a/b//", "https://example.com/a/b"},
+		{"root slash kept", Rules{Slash: true}, "https://example.com/", "https://example.com/"},
+		{"https", Rules{HTTPS: true}, "http://example.com:80/a", "https://example.com/a"},
+		{"https keeps other port", Rules{HTTPS: true}, "http://example.com:8080/a", "https://example.com:8080/a"},
+		{"no rules", Rules{}, "https://Example.com:443/a?utm_source=x#f", "https://Example.com:443/a?utm_source=x#f"},
+		{"query without tracking", Rules{Query: true}, "https://example.com/?utm_source=x&a=1", "https://example.com/?a=1&utm_source=x"},
+		{"extra params", Rules{Tracking: true, Extra: []string{"ref", "share_*"}}, "https://example.com/?ref=hn&share_id=1&shared=2&x=1", "https://example.com/?shared=2&x=1"},
+		{"not http", def, "mailto:someone@example.com", "mailto:someone@example.com"},
+		{"relative", def, "/local/path?utm_source=x", "/local/path?utm_source=x"},
+		{"unparsable", def, "https://exa mple.com/%zz", "https://exa mple.com/%zz"},
+	}
+	for _, tt := range tests {
+		if got := tt.rules.Normalize(tt.in); got != tt.want {
+			t.Errorf("%s: Normalize(%q) = %q, want %q", tt.name, tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestParseRules(t *testing.T) {
+	tests := []struct {
+		list, extra string
+		want        Rules
+		err         string
+	}{
+		{list: DefaultRules, want: Rules{Tracking: true, Host: true, Port: true, Query: true, Fragment: true}},
+		{list: " Slash , HTTPS,", want: Rules{Slash: true, HTTPS: true}},
+		{list: "", want: Rules{}},
+		{list: "tracking", extra: "ref, share_*,,", want: Rules{Tracking: true, Extra: []string{"ref", "share_*"}}},
+		{list: "host,www", err: `unknown URL rule "www" (valid: tracking, host, port, query, fragment, slash, https)`},
+	}
+	for _, tt := range tests {
+		got, err := ParseRules(tt.list, tt.extra)
+		if tt.err != "" {
+			if err == nil || err.Error() != tt.err {
+				t.Errorf("ParseRules(%q) error = %v, want %q", tt.list, err, tt.err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ParseRules(%q): %v", tt.list, err)
+			continue
+		}
+		if got.Tracking != tt.want.Tracking || got.Host != tt.want.Host || got.Port != tt.want.Port ||
+			got.Query != tt.want.Query || got.Fragment != tt.want.Fragment || got.Slash != tt.want.Slash ||
+			got.HTTPS != tt.want.HTTPS || strings.Join(got.Extra, ",") != strings.Join(tt.want.Extra, ",") {
+			t.Errorf("ParseRules(%q, %q) = %+v, want %+v", tt.list, tt.extra, got, tt.want)
+		}
+	}
+}
+
+func TestKey(t *testing.T) {
+	// Key applies every rule whatever r enables, but only r's extra tracking parameters.
+	r := Rules{Extra: []string{"ref"}}
+	same := [][]string{
+		{"https://example.com/a", "http://Example.com:80/a/", "https://example.com/a?utm_source=x#top", "https://example.com/a?ref=hn"},
+		{"https://example.com/?b=2&a=1", "http://example.com?a=1&b=2"},
+	}
+	for _, group := range same {
+		for _, u := range group[1:] {
+			if r.Key(u) != r.Key(group[0]) {
+				t.Errorf("Key(%q) = %q, want the same as Key(%q) = %q", u, r.Key(u), group[0], r.Key(group[0]))
+			}
+		}
+	}
+	different := [][2]string{
+		{"https://example.com/a", "https://example.com/b"},
+		{"https://example.com/a?id=1", "https://example.com/a?id=2"},
+		{"https://example.com/a", "https://www.example.com/a"},
+		{"https://example.com/a", "https://example.com:8443/a"},
+		{"https://app.example.com/#/inbox", "https://app.example.com/#/sent"},
+	}
+	for _, d := range different {
+		if r.Key(d[0]) == r.Key(d[1]) {
+			t.Errorf("Key(%q) and Key(%q) are both %q", d[0], d[1], r.Key(d[0]))
+		}
+	}
+	if none := (Rules{}); none.Key("https://example.com/?ref=hn") == none.Key("https://example.com/") {
+		t.Error("ref is only a tracking parameter when listed in Extra")
+	}
+}
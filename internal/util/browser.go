@@ -1,22 +1,72 @@
 package util
 
 import (
+	"os"
 	"os/exec"
+	"path/filepath"
 	"runtime"
+	"strings"
 )
 
-func OpenBrowser(url string) error {
+// OpenBrowser opens url with the given browser command. The command may include arguments
+// and a {url} placeholder, e.g. "firefox -P work {url}"; without a placeholder the URL is
+// appended. When browser is empty the first available command in $BROWSER (a colon-separated
+// list, where %s also stands for the URL) is used, else the platform default opener.
+func OpenBrowser(browser, url string) error {
+	if strings.TrimSpace(browser) == "" {
+		browser = BrowserFromEnv()
+	}
 	var cmd string
 	var args []string
-	switch runtime.GOOS {
-	case "darwin":
-		cmd = "open"
-	case "windows":
-		cmd = "rundll32"
-		args = []string{"url.dll,FileProtocolHandler"}
-	default:
-		cmd = "xdg-open"
+	if fields := strings.Fields(browser); len(fields) > 0 {
+		cmd, args = fields[0], fields[1:]
+	} else {
+		switch runtime.GOOS {
+		case "darwin":
+			cmd = "open"
+		case "windows":
+			cmd = "rundll32"
+			args = []string{"url.dll,FileProtocolHandler"}
+		default:
+			cmd = "xdg-open"
+		}
+	}
+	return exec.Command(cmd, WithURL(cmd, args, url)...).Start()
+}
+
+// WithURL substitutes url for {url} (or %s) in the arguments of cmd, or appends it when
+// there is no placeholder. ssh joins its arguments into a line for the remote shell, so for
+// ssh the URL is shell-quoted: write "ssh host open {url}", without quotes of your own.
+func WithURL(cmd string, args []string, url string) []string {
+	if name := strings.TrimSuffix(filepath.Base(cmd), ".exe"); name == "ssh" {
+		url = ShellQuote(url)
+	}
+	out := make([]string, len(args))
+	found := false
+	for i, a := range args {
+		if strings.Contains(a, "{url}") || strings.Contains(a, "%s") {
+			found = true
+			a = strings.NewReplacer("{url}", url, "%s", url).Replace(a)
+		}
+		out[i] = a
 	}
-	args = append(args, url)
-	return exec.Command(cmd, args...).Start()
+	if !found {
+		out = append(out, url)
+	}
+	return out
 }
+
+// BrowserFromEnv returns the first command listed in $BROWSER that can be found.
+func BrowserFromEnv() string {
+	for _, c := range strings.Split(os.Getenv("BROWSER"), string(os.PathListSeparator)) {
+		if fields := strings.Fields(c); len(fields) > 0 {
+			if _, err := exec.LookPath(fields[0]); err == nil {
+				return c
+			}
+		}
+	}
+	return ""
+}
+
+// ShellQuote quotes s as a single word for a POSIX shell.
+func ShellQuote(s string) string { return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'" }
@@ -0,0 +1,87 @@
+package util
+
+import (
+	"os/exec"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestWithURL(t *testing.T) {
+	const u = "https://example.com/?a=1&b=2;x='y'"
+	tests := []struct {
+		cmd  string
+		args []string
+		want []string
+	}{
+		{"firefox", nil, []string{u}},
+		{"firefox", []string{"-P", "work"}, []string{"-P", "work", u}},
+		{"firefox", []string{"-P", "work", "{url}", "--new-tab"}, []string{"-P", "work", u, "--new-tab"}},
+		{"lynx", []string{"%s"}, []string{u}},
+		{"chromium", []string{"--app={url}"}, []string{"--app=" + u}},
+		{"ssh", []string{"laptop", "open", "{url}"}, []string{"laptop", "open", `'https://example.com/?a=1&b=2;x='\''y'\'''`}},
+		{"/usr/bin/ssh", []string{"laptop", "open"}, []string{"laptop", "open", `'https://example.com/?a=1&b=2;x='\''y'\'''`}},
+	}
+	for _, tt := range tests {
+		got := WithURL(tt.cmd, tt.args, u)
+		if strings.Join(got, "\x00") != strings.Join(tt.want, "\x00") {
+			t.Errorf("WithURL(%q, %q) = %q, want %q", tt.cmd, tt.args, got, tt.want)
+		}
+	}
+}
+
+// TestWithURLSSH runs the arguments ssh would send through a shell, as the remote host does.
+func TestWithURLSSH(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("needs a POSIX shell")
+	}
+	for _, u := range []string{
+		"https://example.com/search?q=go&page=2",
+		"https://example.com/a;b $(echo no) `echo no` 'quoted' \"double\"",
+	} {
+		args := WithURL("ssh", []string{"laptop", "printf", "{url}"}, u)
+		out, err := exec.Command("sh", "-c", strings.Join(args[1:], " ")).Output()
+		if err != nil || string(out) != u {
+			t.Errorf("remote shell got %q (%v), want %q", out, err, u)
+		}
+	}
+}
+
+func TestShellQuote(t *testing.T) {
+	tests := map[string]string{
+		"":      "''",
+		"plain": "'plain'",
+		"it's":  `'it'\''s'`,
+		"a b&c": "'a b&c'",
+	}
+	for in, want := range tests {
+		if got := ShellQuote(in); got != want {
+			t.Errorf("ShellQuote(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestBrowserFromEnv(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("needs sh on PATH")
+	}
+	sh, err := exec.LookPath("sh")
+	if err != nil {
+		t.Skip("no sh:", err)
+	}
+	tests := []struct {
+		env  string
+		want string
+	}{
+		{"", ""},
+		{"no-such-browser", ""},
+		{"no-such-browser:sh -c 'echo %s'", "sh -c 'echo %s'"},
+		{sh + ":no-such-browser", sh},
+	}
+	for _, tt := range tests {
+		t.Setenv("BROWSER", tt.env)
+		if got := BrowserFromEnv(); got != tt.want {
+			t.Errorf("BROWSER=%q: BrowserFromEnv = %q, want %q", tt.env, got, tt.want)
+		}
+	}
+}
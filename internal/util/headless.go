@@ -0,0 +1,22 @@
+package util
+
+import (
+	"os"
+	"runtime"
+)
+
+// SSHSession reports whether markdex runs inside an SSH session.
+func SSHSession() bool {
+	return os.Getenv("SSH_CONNECTION") != "" || os.Getenv("SSH_CLIENT") != "" || os.Getenv("SSH_TTY") != ""
+}
+
+// Headless reports whether a browser opened here would not be seen: on Linux and the BSDs
+// when there is no X11 or Wayland display (an SSH session with X forwarding still has one),
+// elsewhere when running over SSH.
+func Headless() bool {
+	switch runtime.GOOS {
+	case "darwin", "windows":
+		return SSHSession()
+	}
+	return os.Getenv("DISPLAY") == "" && os.Getenv("WAYLAND_DISPLAY") == ""
+}
@@ -0,0 +1,20 @@
+package util
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// StateDir returns the directory for markdex's local state (history, queued events):
+// $XDG_STATE_HOME/markdex, by default ~/.local/state/markdex.
+func StateDir() string {
+	dir := os.Getenv("XDG_STATE_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return ".markdex"
+		}
+		dir = filepath.Join(home, ".local", "state")
+	}
+	return filepath.Join(dir, "markdex")
+}